@@ -0,0 +1,35 @@
+package main
+
+import (
+	"awesomeProject2/tiling"
+	"os"
+	"path/filepath"
+)
+
+func loadCheckpoint(path string) (*tiling.Checkpoint, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return tiling.ReadCheckpoint(file)
+}
+
+// saveCheckpoint replaces the checkpoint file through a temporary file, so
+// a crash while writing keeps the previous checkpoint.
+func saveCheckpoint(path string, cp *tiling.Checkpoint) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if err := tiling.WriteCheckpoint(tmp, cp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"awesomeProject2/cube"
+	"awesomeProject2/render"
+	"awesomeProject2/tiling"
+	"context"
+	"flag"
+	"fmt"
+	"image/color"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+)
+
+// runCube implements the "cube" subcommand: it splits an N×N×N block into
+// the fewest cubes, verifies the split and optionally writes it as an OBJ
+// model and as one image per layer.
+func runCube(args []string) error {
+	fs := flag.NewFlagSet("cube", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 0, "Stop the search after this time and print the best split found (0 means no limit)")
+	plain := fs.Bool("plain", false, "Search the whole block, without scaling and the corner cubes")
+	objPath := fs.String("obj", "", "Write the split as a Wavefront OBJ model to this file")
+	layersPath := fs.String("layers", "", "Draw every layer of the block, to <name>_<layer><ext> for this path")
+	cellSize := fs.Int("cell", 50, "Side of a cell in the layer images, in pixels or points")
+	palette := fs.String("palette", "", "Comma separated #rrggbb colors used for the cubes instead of random ones")
+	labels := fs.Bool("labels", false, "Write the size of every cube in the layer images")
+	seed := fs.Int64("seed", 1, "Seed of the random cube colors")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cube [flags] N")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected the block size N")
+	}
+	N, err := strconv.Atoi(fs.Arg(0))
+	if err != nil || N <= 0 {
+		return fmt.Errorf("invalid block size %q", fs.Arg(0))
+	}
+	out := imageOutput{path: *layersPath, opts: render.DefaultOptions()}
+	out.opts.CellSize, out.opts.Seed, out.opts.Labels = *cellSize, *seed, *labels
+	if *layersPath != "" {
+		if out.opts.Format, err = render.FormatFromPath(*layersPath); err != nil {
+			return err
+		}
+	}
+	if out.opts.Palette, err = render.ParsePalette(*palette); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	solver := cube.NewSolver()
+	solver.Shortcuts = !*plain
+	if n, cubeSize := tiling.ScaleSize(N); solver.Shortcuts && cubeSize != 1 {
+		fmt.Printf("Scaled block size: %d, Cube size: %d\n", n, cubeSize)
+	}
+	start := time.Now()
+	cubes, err := solver.Solve(ctx, N)
+	if err != nil {
+		return err
+	}
+	if err := cube.Verify(N, cubes); err != nil {
+		return err
+	}
+
+	fmt.Println("Time to solve:", time.Since(start))
+	fmt.Println("Iterations:", solver.Iterations)
+	if !solver.Optimal {
+		fmt.Println("Search stopped before it finished, the result is not proven optimal")
+	}
+	if err := cube.WriteText(os.Stdout, cubes); err != nil {
+		return err
+	}
+
+	if *objPath != "" {
+		if err := saveOBJ(*objPath, cubes); err != nil {
+			return err
+		}
+		fmt.Println("Model written to", *objPath)
+	}
+	if *layersPath != "" {
+		if err := saveLayers(out, N, cubes); err != nil {
+			return err
+		}
+		fmt.Printf("%d layers written to %s\n", N, out.numbered(1))
+	}
+	return nil
+}
+
+func saveOBJ(path string, cubes []cube.Cube) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := cube.WriteOBJ(file, cubes); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// saveLayers draws layer x of the block to the x-th numbered image. Each
+// cube keeps its color in all the layers it crosses.
+func saveLayers(out imageOutput, N int, cubes []cube.Cube) error {
+	colors := cubeColors(len(cubes), out.opts)
+	for x := 0; x < N; x++ {
+		squares, index := cube.Slice(cubes, x)
+		opts := out.opts
+		opts.Palette = make([]color.Color, len(index))
+		for i, c := range index {
+			opts.Palette[i] = colors[c]
+		}
+		if err := saveLayoutGraphic(out.numbered(x+1), opts, &tiling.Layout{Rows: N, Cols: N}, squares); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cubeColors picks the color of every cube the way render picks the
+// colors of squares.
+func cubeColors(count int, opts render.Options) []color.Color {
+	colors := make([]color.Color, count)
+	random := rand.New(rand.NewSource(opts.Seed))
+	for i := range colors {
+		if len(opts.Palette) > 0 {
+			colors[i] = opts.Palette[i%len(opts.Palette)]
+		} else {
+			colors[i] = color.RGBA{R: uint8(random.Intn(256)), G: uint8(random.Intn(256)), B: uint8(random.Intn(256)), A: 255}
+		}
+	}
+	return colors
+}
@@ -0,0 +1,67 @@
+package cube
+
+import (
+	"awesomeProject2/internal/blocks"
+	"awesomeProject2/tiling"
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// ReadText reads a split as written by WriteText, the cube count and
+// then the 1-based corner and the size of every cube.
+func ReadText(r io.Reader) ([]Cube, error) {
+	records, err := blocks.ReadText(r, "cube", "x y z size")
+	if err != nil {
+		return nil, err
+	}
+	cubes := make([]Cube, len(records))
+	for i, v := range records {
+		cubes[i] = Cube{v[0] - 1, v[1] - 1, v[2] - 1, v[3]}
+	}
+	return cubes, nil
+}
+
+// WriteText prints the cube count followed by one Cube.String line per
+// cube.
+func WriteText(w io.Writer, cubes []Cube) error {
+	return blocks.WriteText(w, cubes)
+}
+
+// WriteOBJ writes the cubes as Wavefront OBJ, one object with eight
+// vertices and six quad faces per cube, in cell units.
+func WriteOBJ(w io.Writer, cubes []Cube) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %d cubes\n", len(cubes))
+	for i, c := range cubes {
+		fmt.Fprintf(bw, "o cube_%d_size_%d\n", i+1, c.Size)
+		for corner := 0; corner < 8; corner++ {
+			fmt.Fprintf(bw, "v %d %d %d\n",
+				c.X+c.Size*(corner>>2&1), c.Y+c.Size*(corner>>1&1), c.Z+c.Size*(corner&1))
+		}
+		// The corners of every face, counter-clockwise seen from outside.
+		v := 8*i + 1
+		for _, face := range [6][4]int{
+			{0, 1, 3, 2}, {4, 6, 7, 5}, {0, 4, 5, 1},
+			{2, 3, 7, 6}, {0, 2, 6, 4}, {1, 5, 7, 3},
+		} {
+			fmt.Fprintf(bw, "f %d %d %d %d\n", v+face[0], v+face[1], v+face[2], v+face[3])
+		}
+	}
+	return bw.Flush()
+}
+
+// Slice returns the squares in which layer x of the block cuts the cubes,
+// with Y as their row and Z as their column. The slices of an exact split
+// are exact tilings of the n×n board. The second result holds the index
+// of every square's cube in cubes.
+func Slice(cubes []Cube, x int) ([]tiling.Square, []int) {
+	squares, index := []tiling.Square{}, []int{}
+	for i, c := range cubes {
+		if c.X <= x && x < c.X+c.Size {
+			squares = append(squares, tiling.Square{X: c.Y, Y: c.Z, Size: c.Size})
+			index = append(index, i)
+		}
+	}
+	return squares, index
+}
@@ -0,0 +1,103 @@
+package cube
+
+import "math/bits"
+
+// MaxSide is the largest block the search handles after scaling. Every
+// line of cells along z is stored in one 64-bit word.
+const MaxSide = 64
+
+// grid is the occupancy of an n×n×n block. Line (x, y) is a bitmask, bit z
+// being set when cell (x, y, z) is occupied.
+type grid struct {
+	n     int
+	cells []uint64
+}
+
+func initializeGrid(n int) *grid {
+	return &grid{n: n, cells: make([]uint64, n*n)}
+}
+
+// lineMask returns the bits of cells [z, z+size) of a line.
+func lineMask(z, size int) uint64 {
+	return (^uint64(0) >> (64 - size)) << z
+}
+
+func (g *grid) isOccupied(x, y, z int) bool {
+	return g.cells[x*g.n+y]&(1<<z) != 0
+}
+
+func (g *grid) canPlace(x, y, z, size int) bool {
+	mask := lineMask(z, size)
+	for i := x; i < x+size; i++ {
+		for j := y; j < y+size; j++ {
+			if g.cells[i*g.n+j]&mask != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (g *grid) placeCube(x, y, z, size int) Cube {
+	mask := lineMask(z, size)
+	for i := x; i < x+size; i++ {
+		for j := y; j < y+size; j++ {
+			g.cells[i*g.n+j] |= mask
+		}
+	}
+	return Cube{x, y, z, size}
+}
+
+func (g *grid) removeCube(c Cube) {
+	mask := lineMask(c.Z, c.Size)
+	for i := c.X; i < c.X+c.Size; i++ {
+		for j := c.Y; j < c.Y+c.Size; j++ {
+			g.cells[i*g.n+j] &^= mask
+		}
+	}
+}
+
+// findFirstFreePosition returns the first free cell in (x, y, z) order
+// starting from pos, where pos is (x*n+y)*n+z, or -1 if the block is full.
+func (g *grid) findFirstFreePosition(pos int) int {
+	line, z := pos/g.n, pos%g.n
+	full := lineMask(0, g.n)
+	for ; line < len(g.cells); line, z = line+1, 0 {
+		if free := ^g.cells[line] & full & (^uint64(0) << z); free != 0 {
+			return line*g.n + bits.TrailingZeros64(free)
+		}
+	}
+	return -1
+}
+
+// freeRun returns the number of free cells of line (x, y) from z on.
+func (g *grid) freeRun(x, y, z int) int {
+	used := g.cells[x*g.n+y] >> z
+	if used == 0 {
+		return g.n - z
+	}
+	return bits.TrailingZeros64(used)
+}
+
+// freeCellsFrom counts the free cells in layers x and below, and in layer
+// x alone.
+func (g *grid) freeCellsFrom(x int) (int, int) {
+	used, layer := 0, 0
+	for line := x * g.n; line < len(g.cells); line++ {
+		used += bits.OnesCount64(g.cells[line])
+		if line == (x+1)*g.n-1 {
+			layer = g.n*g.n - used
+		}
+	}
+	return (g.n-x)*g.n*g.n - used, layer
+}
+
+// largestFit returns the largest cube not above maxSz that fits at the
+// free cell (x, y, z). Every smaller cube fits as well.
+func (g *grid) largestFit(x, y, z, maxSz int) int {
+	size := min(maxSz, g.freeRun(x, y, z))
+	for size > 1 && !g.canPlace(x, y, z, size) {
+		size--
+	}
+	return size
+}
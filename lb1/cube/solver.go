@@ -0,0 +1,183 @@
+// Package cube splits an N×N×N block into the fewest smaller cubes. It is
+// the 3D counterpart of the board search in package tiling.
+package cube
+
+import (
+	"awesomeProject2/internal/blocks"
+	"awesomeProject2/tiling"
+	"context"
+	"fmt"
+)
+
+const initialBound = 999999
+
+// Cube is a cube of the split with its corner at cell (X, Y, Z).
+type Cube struct {
+	X, Y, Z, Size int
+}
+
+func (c Cube) String() string {
+	return fmt.Sprintf("%d %d %d %d", c.X+1, c.Y+1, c.Z+1, c.Size)
+}
+
+// Solver keeps the state of a single search, like tiling.Solver.
+type Solver struct {
+	MinCubes   int
+	BestResult []Cube
+	Iterations int
+	// Optimal is false when the search was stopped by its context before
+	// the whole tree was explored, so BestResult is only the best so far.
+	Optimal bool
+
+	// Shortcuts scales a composite block down to its smallest prime
+	// factor and starts from the four corner cubes, as tiling.Solver does
+	// with boards. Without them the whole block is searched.
+	Shortcuts bool
+}
+
+func NewSolver() *Solver {
+	s := &Solver{Shortcuts: true}
+	s.Reset()
+	return s
+}
+
+func (s *Solver) Reset() {
+	s.MinCubes = initialBound
+	s.BestResult = []Cube{}
+	s.Iterations = 0
+	s.Optimal = false
+}
+
+// Solve finds the fewest cubes that split an N×N×N block, none of them the
+// whole block. When ctx is cancelled the best split found so far is
+// returned with s.Optimal set to false, or ctx.Err() if there is none.
+func (s *Solver) Solve(ctx context.Context, N int) ([]Cube, error) {
+	s.Reset()
+	if N < 2 {
+		return s.BestResult, fmt.Errorf("a block of side %d cannot be split", N)
+	}
+	n, cubeSize := N, 1
+	if s.Shortcuts {
+		n, cubeSize = tiling.ScaleSize(N)
+	}
+	if n > MaxSide {
+		return s.BestResult, fmt.Errorf("block side %d is above the largest searched side %d", n, MaxSide)
+	}
+
+	occupied := initializeGrid(n)
+	initialCubes := []Cube{}
+	if s.Shortcuts {
+		initialCubes = placeInitialCubes(n, occupied)
+	}
+	sr := &search{n: n, bound: initialBound, done: ctx.Done()}
+	sr.run(occupied, initialCubes, 0)
+	s.Iterations = sr.iterations
+	s.Optimal = !sr.stopped
+	if sr.best == nil {
+		return s.BestResult, ctx.Err()
+	}
+
+	s.MinCubes, s.BestResult = len(sr.best), sr.best
+	if cubeSize != 1 {
+		s.BestResult = upscaleCubes(s.BestResult, cubeSize)
+	}
+	return s.BestResult, nil
+}
+
+func upscaleCubes(cubes []Cube, scale int) []Cube {
+	result := []Cube{}
+	for _, c := range cubes {
+		result = append(result, Cube{c.X * scale, c.Y * scale, c.Z * scale, c.Size * scale})
+	}
+	return result
+}
+
+// placeInitialCubes puts the largest cube in one corner and the three next
+// largest along its edges, the 3D form of the corner squares of a board.
+func placeInitialCubes(n int, occupied *grid) []Cube {
+	size1, size2 := (n+1)/2, n/2
+	return []Cube{
+		occupied.placeCube(0, 0, 0, size1),
+		occupied.placeCube(0, 0, size1, size2),
+		occupied.placeCube(0, size1, 0, size2),
+		occupied.placeCube(size1, 0, 0, size2),
+	}
+}
+
+// search is the backtracking over the cells of the block in (x, y, z)
+// order, placing the cubes at the first free cell.
+type search struct {
+	n          int
+	bound      int
+	best       []Cube
+	iterations int
+	done       <-chan struct{}
+	stopped    bool
+}
+
+func (sr *search) run(occupied *grid, current []Cube, from int) {
+	sr.iterations++
+	if sr.iterations%blocks.CancelCheckInterval == 0 {
+		select {
+		case <-sr.done:
+			sr.stopped = true
+		default:
+		}
+	}
+	if sr.stopped {
+		return
+	}
+
+	pos := occupied.findFirstFreePosition(from)
+	if pos == -1 {
+		if len(current) < sr.bound {
+			sr.bound = len(current)
+			sr.best = append([]Cube{}, current...)
+		}
+		return
+	}
+
+	x, y, z := pos/(sr.n*sr.n), pos/sr.n%sr.n, pos%sr.n
+	remaining := sr.lowerBound(occupied, x, y, z)
+	if len(current)+remaining >= sr.bound {
+		return
+	}
+	maxSz := min(sr.n-x, sr.n-y, sr.n-z, sr.n-1)
+	maxSz = occupied.largestFit(x, y, z, maxSz)
+
+	for size := maxSz; size >= 1; size-- {
+		c := occupied.placeCube(x, y, z, size)
+		current = append(current, c)
+		if len(current) < sr.bound {
+			sr.run(occupied, current, pos)
+		}
+		current = current[:len(current)-1]
+		occupied.removeCube(c)
+
+		if len(current)+remaining >= sr.bound || sr.stopped {
+			break
+		}
+	}
+}
+
+// lowerBound returns the fewest cubes that can still fill the block when
+// (x, y, z) is the first free cell. Every cube placed from now on has its
+// corner at that cell or after it, so it starts in layer x or below and
+// is no larger than side. The free cells of layer x can only be covered
+// by cubes starting in it, and those of line (x, y) by cubes starting in
+// that line, which gives two more estimates next to the free volume.
+func (sr *search) lowerBound(occupied *grid, x, y, z int) int {
+	side := min(sr.n-x, sr.n-1)
+	free, layer := occupied.freeCellsFrom(x)
+	bound := max(blocks.CeilDiv(free, side*side*side), blocks.CeilDiv(layer, side*side))
+
+	lineSide := min(side, sr.n-y)
+	lineBound := 0
+	for k := z; k < sr.n; k++ {
+		if run := occupied.freeRun(x, y, k); run > 0 {
+			lineBound += blocks.CeilDiv(run, lineSide)
+			k += run
+		}
+	}
+	return max(bound, lineBound)
+}
@@ -0,0 +1,46 @@
+package cube
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// knownCounts are the fewest cubes that split small blocks.
+var knownCounts = []struct{ n, cubes int }{
+	{2, 8}, {3, 20}, {4, 8}, {5, 50}, {6, 8}, {9, 20}, {10, 8},
+}
+
+func TestSolveKnownCounts(t *testing.T) {
+	for _, tc := range knownCounts {
+		for _, shortcuts := range []bool{true, false} {
+			// The plain search of larger blocks takes too long.
+			if !shortcuts && tc.n > 5 {
+				continue
+			}
+			t.Run(fmt.Sprintf("%d/shortcuts=%v", tc.n, shortcuts), func(t *testing.T) {
+				s := NewSolver()
+				s.Shortcuts = shortcuts
+				cubes, err := s.Solve(context.Background(), tc.n)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !s.Optimal {
+					t.Fatal("the search did not finish")
+				}
+				if len(cubes) != tc.cubes || s.MinCubes != tc.cubes {
+					t.Errorf("got %d cubes, want %d", len(cubes), tc.cubes)
+				}
+				if err := Verify(tc.n, cubes); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+func TestSolveTooSmall(t *testing.T) {
+	if _, err := NewSolver().Solve(context.Background(), 1); err == nil {
+		t.Error("a 1×1×1 block was split")
+	}
+}
@@ -0,0 +1,122 @@
+package cube
+
+import (
+	"awesomeProject2/internal/blocks"
+	"fmt"
+	"strings"
+)
+
+// Cell is a unit cell of the block. Its coordinates count from 0, while
+// String prints them from 1.
+type Cell struct {
+	X, Y, Z int
+}
+
+func (c Cell) String() string {
+	return fmt.Sprintf("(%d, %d, %d)", c.X+1, c.Y+1, c.Z+1)
+}
+
+// VerifyError is returned by Verify for a split with faults. Cell lists
+// are cut at blocks.MaxReportedCells.
+type VerifyError struct {
+	// InvalidBlock describes a block without cells. Nothing else is
+	// checked then.
+	InvalidBlock string
+	// Invalid are the cubes of size 0 or less, which fill nothing.
+	Invalid []Cube
+	// OutOfBounds are cells a cube reaches beyond the faces of the block.
+	OutOfBounds []Cell
+	// Overlaps are cells inside two or more cubes.
+	Overlaps []Cell
+	// Gaps are cells left empty.
+	Gaps []Cell
+}
+
+func (e *VerifyError) Error() string {
+	var parts []string
+	if e.InvalidBlock != "" {
+		parts = append(parts, "invalid block: "+e.InvalidBlock)
+	}
+	if len(e.Invalid) > 0 {
+		parts = append(parts, "invalid cubes: "+blocks.JoinShort(e.Invalid))
+	}
+	if len(e.OutOfBounds) > 0 {
+		parts = append(parts, "out of bounds: "+blocks.JoinShort(e.OutOfBounds))
+	}
+	if len(e.Overlaps) > 0 {
+		parts = append(parts, "overlaps: "+blocks.JoinShort(e.Overlaps))
+	}
+	if len(e.Gaps) > 0 {
+		parts = append(parts, "gaps: "+blocks.JoinShort(e.Gaps))
+	}
+	return "invalid split: " + strings.Join(parts, "; ")
+}
+
+func (e *VerifyError) empty() bool {
+	return e.InvalidBlock == "" && len(e.Invalid) == 0 && len(e.OutOfBounds) == 0 && len(e.Overlaps) == 0 && len(e.Gaps) == 0
+}
+
+// appendOutOfBounds adds the cells of c that lie outside an n×n×n block.
+// The inside part of every line of c is skipped, so the loop stops soon
+// after the report is full.
+func appendOutOfBounds(cells []Cell, c Cube, n int) []Cell {
+	inside := func(v int) bool { return v >= 0 && v < n }
+	for i := c.X; i < c.X+c.Size && len(cells) < blocks.MaxReportedCells; i++ {
+		for j := c.Y; j < c.Y+c.Size && len(cells) < blocks.MaxReportedCells; j++ {
+			for k := c.Z; k < c.Z+c.Size && len(cells) < blocks.MaxReportedCells; k++ {
+				if inside(i) && inside(j) && inside(k) {
+					k = n - 1
+					continue
+				}
+				cells = blocks.AppendCell(cells, Cell{i, j, k})
+			}
+		}
+	}
+	return cells
+}
+
+// Verify reports, as a *VerifyError, the cells of the n×n×n block that
+// cubes leave empty, fill twice or reach outside of. An exact split gives
+// nil.
+func Verify(n int, cubes []Cube) error {
+	e := &VerifyError{}
+	if n < 1 {
+		e.InvalidBlock = fmt.Sprintf("side %d has no cells", n)
+		return e
+	}
+	counts := make([]int, n*n*n)
+	for _, c := range cubes {
+		if c.Size <= 0 {
+			e.Invalid = append(e.Invalid, c)
+			continue
+		}
+		x0, x1 := max(c.X, 0), min(c.X+c.Size, n)
+		y0, y1 := max(c.Y, 0), min(c.Y+c.Size, n)
+		z0, z1 := max(c.Z, 0), min(c.Z+c.Size, n)
+		for i := x0; i < x1; i++ {
+			for j := y0; j < y1; j++ {
+				for k := z0; k < z1; k++ {
+					counts[(i*n+j)*n+k]++
+				}
+			}
+		}
+		if x0 != c.X || y0 != c.Y || z0 != c.Z || x1 != c.X+c.Size || y1 != c.Y+c.Size || z1 != c.Z+c.Size {
+			e.OutOfBounds = appendOutOfBounds(e.OutOfBounds, c, n)
+		}
+	}
+
+	for cell, count := range counts {
+		x, y, z := cell/(n*n), cell/n%n, cell%n
+		switch {
+		case count > 1:
+			e.Overlaps = blocks.AppendCell(e.Overlaps, Cell{x, y, z})
+		case count == 0:
+			e.Gaps = blocks.AppendCell(e.Gaps, Cell{x, y, z})
+		}
+	}
+
+	if e.empty() {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,89 @@
+package cube
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// corners splits the 2×2×2 block into its eight cells.
+func corners() []Cube {
+	var cubes []Cube
+	for i := 0; i < 8; i++ {
+		cubes = append(cubes, Cube{i >> 2 & 1, i >> 1 & 1, i & 1, 1})
+	}
+	return cubes
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name  string
+		cubes []Cube
+		check func(*VerifyError) bool
+	}{
+		{"exact", corners(), nil},
+		{"gap", corners()[1:], func(e *VerifyError) bool {
+			return slices.Equal(e.Gaps, []Cell{{0, 0, 0}}) && len(e.Overlaps) == 0
+		}},
+		{"overlap", append(corners(), Cube{1, 1, 1, 1}), func(e *VerifyError) bool {
+			return slices.Equal(e.Overlaps, []Cell{{1, 1, 1}}) && len(e.Gaps) == 0
+		}},
+		{"out of bounds", append(corners()[:7], Cube{1, 1, 1, 2}), func(e *VerifyError) bool {
+			return len(e.OutOfBounds) == 7 && len(e.Gaps) == 0 && len(e.Overlaps) == 0
+		}},
+		{"invalid", append(corners(), Cube{0, 0, 0, 0}), func(e *VerifyError) bool {
+			return len(e.Invalid) == 1 && len(e.Gaps) == 0 && len(e.Overlaps) == 0
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify(2, tc.cubes)
+			if tc.check == nil {
+				if err != nil {
+					t.Errorf("got %v, want nil", err)
+				}
+				return
+			}
+			var e *VerifyError
+			if !errors.As(err, &e) {
+				t.Fatalf("got %v, want a *VerifyError", err)
+			}
+			if !tc.check(e) {
+				t.Errorf("got %+v", e)
+			}
+		})
+	}
+}
+
+// A block without cells is reported instead of panicking.
+func TestVerifyInvalidBlock(t *testing.T) {
+	for _, n := range []int{0, -1, -3} {
+		var e *VerifyError
+		if err := Verify(n, corners()); !errors.As(err, &e) || e.InvalidBlock == "" {
+			t.Errorf("n=%d: got %v, want an invalid block", n, err)
+		}
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	want := append(corners()[:7], Cube{1, 1, 1, 1})
+	var buf bytes.Buffer
+	if err := WriteText(&buf, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadText(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, text := range []string{"2\n1 1 1 1\n", "1 1 1\n", "1 1 x 1\n", "-1\n"} {
+		if _, err := ReadText(strings.NewReader(text)); err == nil {
+			t.Errorf("%q was read without an error", text)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Package dlx solves exact cover problems with Knuth's Algorithm X on
+// dancing links. A problem is a 0/1 matrix given row by row; a cover is a
+// set of rows that has exactly one 1 in every primary column and at most
+// one in every secondary column.
+package dlx
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// cancelCheckInterval is how many search nodes pass between two checks of
+// the context.
+const cancelCheckInterval = 1024
+
+// Matrix is a sparse exact cover matrix. Node 0 is the root, nodes 1 to
+// the column count are the column headers and the rest are the 1s of the
+// rows, linked in four directions.
+type Matrix struct {
+	primary, columns int
+	left, right      []int
+	up, down         []int
+	column           []int
+	row              []int
+	size             []int
+	rowLen           []int
+
+	// weight is what covering a primary column takes off the lower bound
+	// of MinCover, remaining is the sum over the uncovered ones.
+	weight    []float64
+	remaining float64
+
+	// Nodes is the number of search nodes the last search visited.
+	Nodes int
+}
+
+// New returns an empty matrix with primary columns 0 to primary-1 and
+// secondary columns after them.
+func New(primary, secondary int) *Matrix {
+	n := primary + secondary
+	m := &Matrix{
+		primary: primary,
+		columns: n,
+		left:    make([]int, n+1),
+		right:   make([]int, n+1),
+		up:      make([]int, n+1),
+		down:    make([]int, n+1),
+		column:  make([]int, n+1),
+		row:     make([]int, n+1),
+		size:    make([]int, n+1),
+		weight:  make([]float64, n+1),
+	}
+	for c := 0; c <= n; c++ {
+		m.up[c], m.down[c], m.column[c], m.row[c] = c, c, c, -1
+		// Only primary columns are linked to the root, so secondary ones
+		// are never chosen for branching.
+		m.left[c], m.right[c] = c, c
+	}
+	for c := 1; c <= primary; c++ {
+		m.left[c], m.right[c] = c-1, (c+1)%(primary+1)
+	}
+	m.left[0] = primary
+	m.right[0] = 1 % (primary + 1)
+	return m
+}
+
+// Rows returns the number of rows added so far.
+func (m *Matrix) Rows() int {
+	return len(m.rowLen)
+}
+
+// AddRow adds a row with 1s in the given columns and returns its index.
+// Rows are tried in the order they were added.
+func (m *Matrix) AddRow(columns []int) (int, error) {
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("row %d is empty", m.Rows())
+	}
+	seen := map[int]bool{}
+	for _, c := range columns {
+		if c < 0 || c >= m.columns {
+			return 0, fmt.Errorf("row %d: column %d out of range", m.Rows(), c)
+		}
+		if seen[c] {
+			return 0, fmt.Errorf("row %d: column %d repeated", m.Rows(), c)
+		}
+		seen[c] = true
+	}
+
+	r := m.Rows()
+	first := len(m.column)
+	m.rowLen = append(m.rowLen, len(columns))
+	for i, c := range columns {
+		header := c + 1
+		node := len(m.column)
+		m.column = append(m.column, header)
+		m.row = append(m.row, r)
+		m.up = append(m.up, m.up[header])
+		m.down = append(m.down, header)
+		m.down[m.up[header]] = node
+		m.up[header] = node
+		m.size[header]++
+		m.left = append(m.left, first+(i+len(columns)-1)%len(columns))
+		m.right = append(m.right, first+(i+1)%len(columns))
+	}
+	return r, nil
+}
+
+// cover removes column c and every row with a 1 in it.
+func (m *Matrix) cover(c int) {
+	m.right[m.left[c]], m.left[m.right[c]] = m.right[c], m.left[c]
+	m.remaining -= m.weight[c]
+	for i := m.down[c]; i != c; i = m.down[i] {
+		for j := m.right[i]; j != i; j = m.right[j] {
+			m.down[m.up[j]], m.up[m.down[j]] = m.down[j], m.up[j]
+			m.size[m.column[j]]--
+		}
+	}
+}
+
+// uncover undoes cover(c).
+func (m *Matrix) uncover(c int) {
+	for i := m.up[c]; i != c; i = m.up[i] {
+		for j := m.left[i]; j != i; j = m.left[j] {
+			m.size[m.column[j]]++
+			m.down[m.up[j]], m.up[m.down[j]] = j, j
+		}
+	}
+	m.remaining += m.weight[c]
+	m.right[m.left[c]], m.left[m.right[c]] = c, c
+}
+
+// search is one run of Algorithm X. prune, when set, cuts the subtree of a
+// partial cover of depth rows. visit gets every cover and returns false to
+// end the search.
+type search struct {
+	m        *Matrix
+	ctx      context.Context
+	prune    func(depth int) bool
+	visit    func(rows []int) bool
+	solution []int
+	stopped  bool
+	err      error
+}
+
+func (s *search) run(depth int) {
+	m := s.m
+	m.Nodes++
+	if m.Nodes%cancelCheckInterval == 0 && s.ctx.Err() != nil {
+		s.err, s.stopped = s.ctx.Err(), true
+	}
+	if s.stopped {
+		return
+	}
+	if m.right[0] == 0 {
+		s.stopped = !s.visit(s.solution)
+		return
+	}
+	if s.prune != nil && s.prune(depth) {
+		return
+	}
+
+	// Branch on the column with the fewest rows left.
+	c := m.right[0]
+	for j := m.right[c]; j != 0; j = m.right[j] {
+		if m.size[j] < m.size[c] {
+			c = j
+		}
+	}
+	if m.size[c] == 0 {
+		return
+	}
+
+	m.cover(c)
+	for r := m.down[c]; r != c && !s.stopped; r = m.down[r] {
+		s.solution = append(s.solution, m.row[r])
+		for j := m.right[r]; j != r; j = m.right[j] {
+			m.cover(m.column[j])
+		}
+		s.run(depth + 1)
+		for j := m.left[r]; j != r; j = m.left[j] {
+			m.uncover(m.column[j])
+		}
+		s.solution = s.solution[:len(s.solution)-1]
+	}
+	m.uncover(c)
+}
+
+// Search calls visit with the rows of every exact cover until visit
+// returns false. The slice passed to visit is reused afterwards. It
+// returns the context error if ctx ended the search.
+func (m *Matrix) Search(ctx context.Context, visit func(rows []int) bool) error {
+	m.Nodes = 0
+	s := &search{m: m, ctx: ctx, visit: visit}
+	s.run(0)
+	return s.err
+}
+
+// MinCover returns an exact cover with the fewest rows, or nil if there is
+// none. With bound above 0 only covers of fewer than bound rows are looked
+// for. Partial covers are cut with a lower bound: a row can cover a column
+// together with at most as many columns as the longest row through it has,
+// so every uncovered column needs at least the inverse of that length of a
+// row. If ctx ends the search, the best cover found so far is returned with
+// the context error.
+func (m *Matrix) MinCover(ctx context.Context, bound int) ([]int, error) {
+	for c := 1; c <= m.primary; c++ {
+		longest := 0
+		for i := m.down[c]; i != c; i = m.down[i] {
+			longest = max(longest, m.rowLen[m.row[i]])
+		}
+		if longest > 0 {
+			m.weight[c] = 1 / float64(longest)
+			m.remaining += m.weight[c]
+		}
+	}
+	defer func() {
+		clear(m.weight)
+		m.remaining = 0
+	}()
+
+	var best []int
+	if bound <= 0 {
+		bound = math.MaxInt
+	}
+	m.Nodes = 0
+	s := &search{m: m, ctx: ctx}
+	s.prune = func(depth int) bool {
+		return depth+int(math.Ceil(m.remaining-1e-9)) >= bound
+	}
+	s.visit = func(rows []int) bool {
+		if len(rows) < bound {
+			best, bound = append([]int{}, rows...), len(rows)
+		}
+		return true
+	}
+	s.run(0)
+	return best, s.err
+}
@@ -0,0 +1,126 @@
+package dlx
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// newMatrix builds a matrix of the given rows, failing the test on an
+// invalid row.
+func newMatrix(t *testing.T, primary, secondary int, rows [][]int) *Matrix {
+	t.Helper()
+	m := New(primary, secondary)
+	for _, row := range rows {
+		if _, err := m.AddRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+// The example of Knuth's "Dancing Links" paper, columns A to G, has the
+// single cover {A D}, {B G}, {C E F}.
+func TestSearchKnuthExample(t *testing.T) {
+	m := newMatrix(t, 7, 0, [][]int{
+		{2, 4, 5},
+		{0, 3, 6},
+		{1, 2, 5},
+		{0, 3},
+		{1, 6},
+		{3, 4, 6},
+	})
+	var covers [][]int
+	err := m.Search(context.Background(), func(rows []int) bool {
+		cover := slices.Clone(rows)
+		slices.Sort(cover)
+		covers = append(covers, cover)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(covers) != 1 || !slices.Equal(covers[0], []int{0, 3, 4}) {
+		t.Errorf("got covers %v, want [[0 3 4]]", covers)
+	}
+}
+
+// Secondary columns may stay uncovered but not be covered twice.
+func TestSearchSecondaryColumns(t *testing.T) {
+	m := newMatrix(t, 2, 1, [][]int{{0, 2}, {1, 2}, {0}, {1}})
+	count := 0
+	if err := m.Search(context.Background(), func([]int) bool { count++; return true }); err != nil {
+		t.Fatal(err)
+	}
+	// {0 2}+{1}, {1 2}+{0} and {0}+{1}, but not {0 2}+{1 2}.
+	if count != 3 {
+		t.Errorf("got %d covers, want 3", count)
+	}
+}
+
+func TestMinCover(t *testing.T) {
+	// Covers of 1, 2 and 4 rows.
+	rows := [][]int{{0}, {1}, {2}, {3}, {0, 1}, {2, 3}, {0, 1, 2, 3}}
+	tests := []struct {
+		bound int
+		want  []int
+	}{
+		{0, []int{6}},
+		{-1, []int{6}},
+		{2, []int{6}},
+		{1, nil},
+	}
+	for _, tc := range tests {
+		m := newMatrix(t, 4, 0, rows)
+		got, err := m.MinCover(context.Background(), tc.bound)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("bound %d: got %v, want %v", tc.bound, got, tc.want)
+		}
+	}
+
+	// Without the whole row the best cover has two rows, and a bound of 2
+	// excludes it.
+	m := newMatrix(t, 4, 0, rows[:6])
+	if got, _ := m.MinCover(context.Background(), 0); len(got) != 2 {
+		t.Errorf("got %v, want a cover of 2 rows", got)
+	}
+	if got, _ := m.MinCover(context.Background(), 2); got != nil {
+		t.Errorf("bound 2: got %v, want none", got)
+	}
+}
+
+func TestSearchCancelled(t *testing.T) {
+	// Every partition of 12 columns into singles and pairs is a cover,
+	// far more than the nodes between two checks of the context.
+	const n = 12
+	var rows [][]int
+	for i := 0; i < n; i++ {
+		rows = append(rows, []int{i})
+		for j := i + 1; j < n; j++ {
+			rows = append(rows, []int{i, j})
+		}
+	}
+	m := newMatrix(t, n, 0, rows)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := m.Search(ctx, func([]int) bool { return true })
+	if !errors.Is(err, context.Canceled) || m.Nodes > 2*cancelCheckInterval {
+		t.Errorf("got %v after %d nodes, want context.Canceled", err, m.Nodes)
+	}
+}
+
+func TestAddRowErrors(t *testing.T) {
+	m := New(3, 1)
+	for _, row := range [][]int{{}, {4}, {-1}, {0, 0}} {
+		if _, err := m.AddRow(row); err == nil {
+			t.Errorf("row %v was accepted", row)
+		}
+	}
+	if m.Rows() != 0 {
+		t.Errorf("got %d rows after errors, want 0", m.Rows())
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"awesomeProject2/model"
+	"awesomeProject2/tiling"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// runExport implements the "export" subcommand: it writes the problem of
+// tiling a board with at most k squares under the size rule as DIMACS CNF
+// or CPLEX LP.
+func runExport(args []string, rule tiling.SizeRule) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	k := fs.Int("k", 0, "Largest number of squares, fixed squares included")
+	format := fs.String("format", "cnf", "Output format: cnf or lp")
+	layoutPath := fs.String("layout", "", "Export the board with obstacles and fixed squares described in this file")
+	outPath := fs.String("o", "", "Output file (default stdout)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: export -k K [flags] [N [M]]")
+		fmt.Fprintln(fs.Output(), "Exports the N×M board, or the board of -layout, with the square sizes of -sizes, -min-size, -max-size and -no-unit.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	layout, err := exportLayout(*layoutPath, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *k <= 0 {
+		return fmt.Errorf("-k must be positive")
+	}
+	if err := rule.Validate(layout.Rows, layout.Cols); err != nil {
+		return err
+	}
+	m, err := model.New(layout, *k, rule)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	switch *format {
+	case "cnf":
+		return m.CNF().WriteDIMACS(out)
+	case "lp":
+		return m.WriteLP(out)
+	}
+	return fmt.Errorf("unknown format %q, expected cnf or lp", *format)
+}
+
+// exportLayout returns the layout of path, or an empty N×M board from the
+// arguments when path is empty.
+func exportLayout(path string, args []string) (*tiling.Layout, error) {
+	if path != "" {
+		return readLayout(path)
+	}
+	if len(args) == 0 || len(args) > 2 {
+		return nil, fmt.Errorf("expected the board size N [M] or -layout")
+	}
+	sizes := make([]int, len(args))
+	for i, arg := range args {
+		var err error
+		if sizes[i], err = strconv.Atoi(arg); err != nil || sizes[i] <= 0 {
+			return nil, fmt.Errorf("invalid board size %q", arg)
+		}
+	}
+	if len(sizes) == 1 {
+		sizes = append(sizes, sizes[0])
+	}
+	return &tiling.Layout{Rows: sizes[0], Cols: sizes[1]}, nil
+}
+
+// runImport implements the "import" subcommand: it turns the assignment a
+// SAT solver found for an exported CNF back into a tiling and verifies it.
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	cnfPath := fs.String("cnf", "", "The exported CNF file the assignment belongs to")
+	rows := fs.Int("n", 0, "Number of rows of the board")
+	cols := fs.Int("m", 0, "Number of columns of the board (defaults to -n)")
+	layoutPath := fs.String("layout", "", "Check the tiling against the layout in this file")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: import -cnf file [flags] [assignment]")
+		fmt.Fprintln(fs.Output(), "Reads the solver output from assignment, or from stdin when no file is given.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *cnfPath == "" {
+		return fmt.Errorf("-cnf is required")
+	}
+	file, err := os.Open(*cnfPath)
+	if err != nil {
+		return err
+	}
+	f, err := model.ReadDIMACS(file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", *cnfPath, err)
+	}
+
+	var in io.Reader = os.Stdin
+	if fs.NArg() > 0 {
+		file, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+	values, err := model.ReadAssignment(in, f.Vars)
+	if err != nil {
+		return err
+	}
+
+	if *cols == 0 {
+		*cols = *rows
+	}
+	layout := &tiling.Layout{Rows: *rows, Cols: *cols}
+	if *layoutPath != "" {
+		if layout, err = readLayout(*layoutPath); err != nil {
+			return err
+		}
+	}
+	if layout.Rows <= 0 || layout.Cols <= 0 {
+		return fmt.Errorf("board size is unknown, pass -n and -m")
+	}
+
+	squares := f.Decode(values, layout.Fixed)
+	if err := tiling.VerifyLayout(layout, squares); err != nil {
+		return err
+	}
+	return tiling.WriteText(os.Stdout, squares)
+}
@@ -1,57 +1,39 @@
 package main
 
 import (
-	"image"
-	"image/color"
-	"image/png"
-	"math/rand"
-	"os"
-	"time"
+	"awesomeProject2/render"
+	"awesomeProject2/tiling"
+	"fmt"
+	"path/filepath"
+	"strings"
 )
 
-func showGraphic(N int, squares []Square) {
-	cellSize := 50
-	imgWidth, imgHeight := N*cellSize, N*cellSize
-	img := image.NewRGBA(image.Rect(0, 0, imgWidth, imgHeight))
+const defaultImagePath = "./lb1/images/squares.png"
 
-	for i := 0; i <= N; i++ {
-		for x := 0; x < imgWidth; x++ {
-			img.Set(x, i*cellSize, color.Black)
-		}
-		for y := 0; y < imgHeight; y++ {
-			img.Set(i*cellSize, y, color.Black)
-		}
-	}
-
-	rand.Seed(time.Now().UnixNano())
-	for _, square := range squares {
-		x, y, size := square.x*cellSize, square.y*cellSize, square.size*cellSize
-		r := uint8(rand.Intn(256))
-		g := uint8(rand.Intn(256))
-		b := uint8(rand.Intn(256))
-		col := color.RGBA{R: r, G: g, B: b, A: 255}
+// imageOutput says where solved tilings are drawn and how.
+type imageOutput struct {
+	path string
+	opts render.Options
+}
 
-		for dx := 0; dx < size; dx++ {
-			for dy := 0; dy < size; dy++ {
-				img.Set(x+dx, y+dy, col)
-			}
-		}
+// numbered returns the path of the i-th of several images, squares_<i>.png
+// for the default path.
+func (o imageOutput) numbered(i int) string {
+	ext := filepath.Ext(o.path)
+	return fmt.Sprintf("%s_%d%s", strings.TrimSuffix(o.path, ext), i, ext)
+}
 
-		borderColor := color.RGBA{R: 0, G: 0, B: 0, A: 255}
-		for dx := 0; dx < size; dx++ {
-			img.Set(x+dx, y, borderColor)
-			img.Set(x+dx, y+size-1, borderColor)
-		}
-		for dy := 0; dy < size; dy++ {
-			img.Set(x, y+dy, borderColor)
-			img.Set(x+size-1, y+dy, borderColor)
-		}
-	}
+// showGraphic draws a board with N rows and M columns. Square.X is the row
+// of a square, so it is drawn along the vertical axis.
+func showGraphic(out imageOutput, N, M int, squares []tiling.Square) error {
+	return saveLayoutGraphic(out.path, out.opts, &tiling.Layout{Rows: N, Cols: M}, squares)
+}
 
-	outFile, err := os.Create("./lb1/images/squares.png")
-	if err != nil {
-		panic(err)
+// saveLayoutGraphic draws a layout with its blocked cells in dark gray,
+// crossed out, under the squares of the tiling.
+func saveLayoutGraphic(path string, opts render.Options, l *tiling.Layout, squares []tiling.Square) error {
+	if err := render.Save(path, l, squares, opts); err != nil {
+		return fmt.Errorf("saving image: %w", err)
 	}
-	defer outFile.Close()
-	png.Encode(outFile, img)
+	return nil
 }
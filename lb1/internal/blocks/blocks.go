@@ -0,0 +1,107 @@
+// Package blocks holds the parts of the square tiling code that do not
+// depend on the dimension of the board: the pacing of the searches, the
+// cell reports of the verifiers and the text format of a list of squares,
+// so that the 3D cube split can use them as well.
+package blocks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CancelCheckInterval is how many nodes a search visits between polls of
+// its context.
+const CancelCheckInterval = 1024
+
+// CeilDiv returns a/b rounded up, for a non-negative a and a positive b.
+func CeilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+// MaxReportedCells limits how many cells of each kind a verifier keeps,
+// so that a wildly wrong result does not produce a huge report.
+const MaxReportedCells = 1000
+
+// AppendCell appends cell unless cells already holds MaxReportedCells.
+func AppendCell[T any](cells []T, cell T) []T {
+	if len(cells) < MaxReportedCells {
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+// JoinShort prints the first few items of a list.
+func JoinShort[T fmt.Stringer](items []T) string {
+	const shown = 10
+	var b strings.Builder
+	for i, item := range items {
+		if i == shown {
+			fmt.Fprintf(&b, " and %d more", len(items)-shown)
+			break
+		}
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(item.String())
+	}
+	return b.String()
+}
+
+// ReadText parses a list of items: an optional line with their number
+// followed by one line per item holding the integer fields named by
+// format, such as "x y size". Empty lines and lines starting with "//"
+// are ignored. noun names an item in the errors.
+func ReadText(r io.Reader, noun, format string) ([][]int, error) {
+	want := len(strings.Fields(format))
+	records := [][]int{}
+	count := -1
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 1 && count == -1 && len(records) == 0 {
+			if _, err := fmt.Sscan(line, &count); err != nil || count < 0 {
+				return nil, fmt.Errorf("line %d: invalid %s count %q", lineNo, noun, line)
+			}
+			continue
+		}
+		if len(fields) != want {
+			return nil, fmt.Errorf("line %d: expected %q, got %q", lineNo, format, line)
+		}
+		record := make([]int, want)
+		for i, field := range fields {
+			var err error
+			if record[i], err = strconv.Atoi(field); err != nil {
+				return nil, fmt.Errorf("line %d: invalid number %q", lineNo, field)
+			}
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if count != -1 && count != len(records) {
+		return nil, fmt.Errorf("expected %d %ss, got %d", count, noun, len(records))
+	}
+	return records, nil
+}
+
+// WriteText writes items in the format read by ReadText, one String per
+// line.
+func WriteText[T fmt.Stringer](w io.Writer, items []T) error {
+	if _, err := fmt.Fprintln(w, len(items)); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if _, err := fmt.Fprintln(w, item.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
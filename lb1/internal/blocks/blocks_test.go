@@ -0,0 +1,33 @@
+package blocks
+
+import (
+	"strconv"
+	"testing"
+)
+
+type number int
+
+func (n number) String() string { return strconv.Itoa(int(n)) }
+
+func TestJoinShort(t *testing.T) {
+	var items []number
+	for i := 1; i <= 12; i++ {
+		items = append(items, number(i))
+	}
+	if got, want := JoinShort(items[:3]), "1, 2, 3"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := JoinShort(items), "1, 2, 3, 4, 5, 6, 7, 8, 9, 10 and 2 more"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestAppendCellStopsAtLimit(t *testing.T) {
+	var cells []int
+	for i := 0; i < MaxReportedCells+5; i++ {
+		cells = AppendCell(cells, i)
+	}
+	if len(cells) != MaxReportedCells {
+		t.Errorf("got %d cells, want %d", len(cells), MaxReportedCells)
+	}
+}
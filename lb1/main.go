@@ -1,49 +1,411 @@
 package main
 
 import (
+	"awesomeProject2/render"
+	"awesomeProject2/store"
+	"awesomeProject2/tiling"
+	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
 	"time"
 )
 
-var minSquares = 999999
-var bestResult []Square
-var iterationsCnt int
-
-type Square struct {
-	x, y, size int
-}
-
-func (s Square) String() string {
-	return fmt.Sprintf("%d %d %d", s.x+1, s.y+1, s.size)
-}
-
 func main() {
 	benchmark := flag.Bool("benchmark", false, "Run mode")
+	benchConfig := DefaultBenchmarkConfig()
+	flag.IntVar(&benchConfig.From, "bench-from", benchConfig.From, "Smallest N solved by the benchmark")
+	flag.IntVar(&benchConfig.To, "bench-to", benchConfig.To, "Largest N solved by the benchmark")
+	flag.StringVar(&benchConfig.Filter, "bench-filter", benchConfig.Filter, "N solved by the benchmark: primes, composites or all")
+	flag.StringVar(&benchConfig.OutDir, "bench-out", benchConfig.OutDir, "Directory for the benchmark CSV, JSON and plots")
+	flag.BoolVar(&benchConfig.LogX, "bench-logx", false, "Plot N on a log scale")
+	flag.BoolVar(&benchConfig.LogY, "bench-logy", false, "Plot the measurements on a log scale")
+	flag.BoolVar(&benchConfig.Unpruned, "bench-unpruned", false, "Also run the search without pruning in the benchmark")
+	flag.BoolVar(&benchConfig.DLX, "bench-dlx", false, "Also run the dlx engine in the benchmark")
+	workers := flag.Int("workers", 1, "Number of goroutines used by the solver")
+	layoutPath := flag.String("layout", "", "Solve the board with obstacles and fixed squares described in this file")
+	enumerate := flag.Bool("enumerate", false, "List all minimum tilings of an N×N board up to symmetry")
+	sizes := flag.String("sizes", "", "Comma separated list of allowed square sizes")
+	minSize := flag.Int("min-size", 0, "Smallest allowed square size")
+	maxSize := flag.Int("max-size", 0, "Largest allowed square size (0 means no limit)")
+	noUnit := flag.Bool("no-unit", false, "Forbid 1×1 squares")
+	perfect := flag.Bool("perfect", false, "Only allow tilings whose squares all differ in size")
+	distinct := flag.Int("distinct", 0, "Largest number of different square sizes (0 means no limit)")
+	coprime := flag.Bool("coprime", false, "Only allow tilings whose square sizes have a gcd of 1 (Mrs. Perkins's quilt)")
+	faultFree := flag.Bool("fault-free", false, "Only allow tilings that no straight line crosses without cutting a square")
+	engine := flag.String("engine", "backtracking", "Search engine: backtracking, skyline, dlx or heuristic")
+	heuristicSteps := flag.Int("heuristic-steps", 0, "Moves of the heuristic engine (0 means the default)")
+	seedBound := flag.Bool("seed-bound", false, "Run the heuristic first and start the exact search from its square count")
+	heuristicSeed := flag.Int64("heuristic-seed", 1, "Seed of the heuristic engine")
+	colorSeed := flag.Int64("color-seed", 1, "Seed of the random square colors")
+	seed := flag.Int64("seed", 1, "Sets both -heuristic-seed and -color-seed unless they are given")
+	asJSON := flag.Bool("json", false, "Print the tiling as JSON")
+	timeout := flag.Duration("timeout", 0, "Stop the search after this time and print the best tiling found (0 means no limit)")
+	outPath := flag.String("out", defaultImagePath, "Image file the tiling is drawn to")
+	format := flag.String("format", "", "Image format: png, svg or pdf (default from the -out extension)")
+	cellSize := flag.Int("cell", 50, "Side of a board cell in the image, in pixels or points")
+	palette := flag.String("palette", "", "Comma separated #rrggbb colors used for the squares instead of random ones")
+	labels := flag.Bool("labels", false, "Write the size of every square in the image")
+	gridLines := flag.Bool("grid", true, "Draw the board grid lines in the image")
+	trace := flag.String("trace", "none", "Print the steps of the sequential search: none, text or json")
+	gifPath := flag.String("gif", "", "Record the sequential search as an animated GIF in this file")
+	gifEvery := flag.Int("gif-every", 1, "Keep one GIF frame per this many placed or removed squares")
+	gifFrames := flag.Int("gif-frames", 500, "Largest number of GIF frames, older frames are thinned out beyond it")
+	checkpointPath := flag.String("checkpoint", "", "Write checkpoints of the sequential search to this file")
+	checkpointEvery := flag.Duration("checkpoint-every", time.Minute, "Time between two checkpoints")
+	resumePath := flag.String("resume", "", "Continue the search saved in this checkpoint file")
+	decompose := flag.Bool("decompose", false, "Tile a composite N×N board from the optimal tilings of its prime factors")
+	decomposeCheck := flag.Int("decompose-check", 16, "Largest N whose decomposition is checked by an exact search of the whole board")
+	storePath := flag.String("store", "", "Answer from this file of proven-optimal tilings and add new ones to it (off by default, \"store prefill\" fills "+defaultStorePath()+")")
 	flag.Parse()
 
+	// -seed predates the two seeds and still sets those not given.
+	given := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	if given["seed"] && !given["heuristic-seed"] {
+		*heuristicSeed = *seed
+	}
+	if given["seed"] && !given["color-seed"] {
+		*colorSeed = *seed
+	}
+
+	// The size rule also applies to the boards of "export".
+	rule := tiling.SizeRule{Min: *minSize, Max: *maxSize}
+	if *noUnit {
+		rule.Min = max(rule.Min, 2)
+	}
+	var err error
+	if rule.Allowed, err = tiling.ParseSizes(*sizes); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	if flag.Arg(0) == "verify" {
+		if err := runVerify(flag.Args()[1:]); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.Arg(0) == "serve" {
+		if err := runServe(flag.Args()[1:]); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.Arg(0) == "export" {
+		if err := runExport(flag.Args()[1:], rule); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.Arg(0) == "import" {
+		if err := runImport(flag.Args()[1:]); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.Arg(0) == "cube" {
+		if err := runCube(flag.Args()[1:]); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.Arg(0) == "store" {
+		if err := runStore(flag.Args()[1:]); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if *benchmark {
-		Benchmark()
+		benchConfig.Workers = *workers
+		if err := Benchmark(benchConfig); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
 		return
 	}
 
-	N := getGridSizeFromUser()
+	var layout *tiling.Layout
+	var N, M int
+	if *layoutPath != "" {
+		if layout, err = readLayout(*layoutPath); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		N, M = layout.Rows, layout.Cols
+	}
+	var resume *tiling.Checkpoint
+	if *resumePath != "" {
+		if resume, err = loadCheckpoint(*resumePath); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		N, M = resume.Rows, resume.Cols
+		fmt.Printf("Resuming the search of the %dx%d board after %d iterations\n", N, M, resume.Iterations)
+	} else if layout == nil {
+		if N, M, err = getGridSizeFromUser(); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+	}
 	start := time.Now()
 
-	solveAndDisplay(N)
+	// Ctrl-C stops the search like a timeout, so the best tiling so far is
+	// printed and a final checkpoint is written.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	solver := tiling.NewSolver()
+	solver.Workers = *workers
+	solver.HeuristicSteps, solver.Seed, solver.SeedBound = *heuristicSteps, *heuristicSeed, *seedBound
+	solver.Resume = resume
+	if *checkpointPath != "" {
+		solver.CheckpointEvery = *checkpointEvery
+		solver.OnCheckpoint = func(cp *tiling.Checkpoint) {
+			if err := saveCheckpoint(*checkpointPath, cp); err != nil {
+				fmt.Println("Error writing checkpoint:", err)
+			}
+		}
+	}
+	solver.Sizes = rule
+	solver.Perfect, solver.DistinctSizes = *perfect, *distinct
+	solver.Coprime, solver.FaultFree = *coprime, *faultFree
+	if solver.Engine, err = tiling.ParseEngine(*engine); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	out := imageOutput{path: *outPath, opts: render.DefaultOptions()}
+	out.opts.CellSize, out.opts.Seed = *cellSize, *colorSeed
+	out.opts.Labels, out.opts.Grid = *labels, *gridLines
+	if *format != "" {
+		out.opts.Format, err = render.ParseFormat(*format)
+	} else {
+		out.opts.Format, err = render.FormatFromPath(*outPath)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	if out.opts.Palette, err = render.ParsePalette(*palette); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	var animation *render.Animation
+	if *gifPath != "" && (solver.Workers > 1 || solver.Engine != tiling.Backtracking) {
+		fmt.Println("Error: -gif records the sequential backtracking search, run it with one worker")
+		os.Exit(1)
+	}
+	if *gifPath != "" && !*enumerate {
+		animLayout := layout
+		if animLayout == nil {
+			animLayout = &tiling.Layout{Rows: N, Cols: M}
+		}
+		opts := render.DefaultAnimationOptions()
+		opts.Every, opts.MaxFrames = *gifEvery, *gifFrames
+		animation = render.NewAnimation(animLayout, opts)
+	}
+	stdout := bufio.NewWriter(os.Stdout)
+	tracer, err := tiling.ParseTracer(*trace, stdout)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	if animation != nil {
+		solver.Tracer = tiling.MultiTracer(tracer, animation)
+	} else {
+		solver.Tracer = tracer
+	}
+
+	var st *store.Store
+	if *storePath != "" && animation == nil && tracer == nil && resume == nil && !*perfect && *distinct == 0 && !*coprime && !*faultFree {
+		if st, err = store.Open(*storePath); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+	}
+
+	if *enumerate {
+		err = enumerateAndDisplay(ctx, solver, out, N)
+	} else if layout != nil {
+		err = solveLayoutAndDisplay(ctx, solver, out, layout)
+	} else if *decompose {
+		err = decomposeAndDisplay(ctx, solver, st, out, N, *decomposeCheck)
+	} else {
+		err = solveAndDisplay(ctx, solver, st, out, N, M)
+	}
+	stdout.Flush()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	if *enumerate {
+		return
+	}
+	if animation != nil {
+		if err := animation.Save(*gifPath); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Animation: %d frames written to %s\n", animation.Frames(), *gifPath)
+	}
 
 	duration := time.Since(start)
 	fmt.Println("Time to solve:", duration)
-	fmt.Println("Iterations:", iterationsCnt)
-	fmt.Println(minSquares)
-	for _, square := range bestResult {
+	fmt.Println("Iterations:", solver.Iterations)
+	if solver.Engine == tiling.Heuristic && !solver.Optimal {
+		fmt.Println("Heuristic result, an upper bound that is not proven optimal")
+	} else if !solver.Optimal && !*decompose {
+		fmt.Println("Search stopped before it finished, the result is not proven optimal")
+	}
+	if *asJSON {
+		if err := tiling.WriteJSON(os.Stdout, &tiling.Tiling{Rows: N, Cols: M, Squares: solver.BestResult}); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Println(solver.MinSquares)
+	for _, square := range solver.BestResult {
 		fmt.Println(square.String())
 	}
+	if solver.Perfect || solver.DistinctSizes > 0 || solver.Coprime {
+		fmt.Println("Sizes:", tiling.FormatSizes(solver.BestResult))
+	}
+}
+
+// solveAndDisplay answers from st when it holds the board and records new
+// optimal tilings in it. st may be nil.
+func solveAndDisplay(ctx context.Context, solver *tiling.Solver, st *store.Store, out imageOutput, N, M int) error {
+	if st != nil {
+		if squares, ok := st.Get(N, M, solver.Sizes); ok {
+			fmt.Println("Answered from the store")
+			solver.Reset()
+			solver.MinSquares, solver.BestResult, solver.Optimal = len(squares), squares, true
+			return showGraphic(out, N, M, squares)
+		}
+	}
+
+	rows, cols, squareSize := tiling.ScaleRect(N, M)
+	if !solver.Sizes.Empty() || solver.Perfect || solver.DistinctSizes > 0 || solver.Coprime || solver.FaultFree {
+		squareSize = 1
+	}
+	if N == M && squareSize != 1 {
+		fmt.Printf("Scaled grid size: %d, Square size: %d\n", rows, squareSize)
+	} else if N != M && squareSize != 1 {
+		fmt.Printf("Scaled grid size: %dx%d, Square size: %d\n", rows, cols, squareSize)
+	}
+
+	squares, err := solver.SolveRect(ctx, N, M)
+	if err != nil {
+		return err
+	}
+	if st != nil && solver.Optimal {
+		if err := storeTiling(st, N, M, solver.Sizes, squares); err != nil {
+			return err
+		}
+	}
+	return showGraphic(out, N, M, squares)
 }
 
-func getGridSizeFromUser() int {
-	var N int
-	fmt.Print("Enter N: ")
-	fmt.Scan(&N)
-	return N
+// decomposeAndDisplay tiles the N×N board from its prime factors, looking
+// them up in st when it is not nil, and explains the choice.
+func decomposeAndDisplay(ctx context.Context, solver *tiling.Solver, st *store.Store, out imageOutput, N, checkUpTo int) error {
+	opts := tiling.DecomposeOptions{CheckUpTo: checkUpTo}
+	if st != nil {
+		opts.Lookup = func(p int) ([]tiling.Square, bool) {
+			return st.Get(p, p, solver.Sizes)
+		}
+	}
+	d, err := solver.Decompose(ctx, N, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Println(d.Explain())
+	return showGraphic(out, N, N, d.Squares)
+}
+
+func solveLayoutAndDisplay(ctx context.Context, solver *tiling.Solver, out imageOutput, layout *tiling.Layout) error {
+	squares, err := solver.SolveLayout(ctx, layout)
+	if err != nil {
+		return err
+	}
+	return saveLayoutGraphic(out.path, out.opts, layout, squares)
+}
+
+// enumerateAndDisplay prints every distinct minimum tiling and renders the
+// i-th of them next to the output image, as squares_<i>.png by default.
+func enumerateAndDisplay(ctx context.Context, solver *tiling.Solver, out imageOutput, N int) error {
+	e, err := solver.Enumerate(ctx, N)
+	if errors.Is(err, tiling.ErrNotProven) {
+		fmt.Printf("Enumeration stopped before the minimum was proven, the best tiling found has %d squares\n", solver.MinSquares)
+	}
+	if err != nil {
+		return err
+	}
+	if !e.Complete {
+		fmt.Println("Enumeration stopped before it finished, the lists are incomplete")
+	}
+	fmt.Println("Minimum squares:", e.MinSquares)
+	fmt.Println("Tilings:", len(e.Tilings))
+	fmt.Println("Tilings up to symmetry:", len(e.Distinct))
+	for i, squares := range e.Distinct {
+		path := out.numbered(i + 1)
+		fmt.Printf("--- Tiling %d (%s) ---\n", i+1, path)
+		for _, square := range squares {
+			fmt.Println(square.String())
+		}
+		if solver.Perfect || solver.DistinctSizes > 0 || solver.Coprime {
+			fmt.Println("Sizes:", tiling.FormatSizes(squares))
+		}
+		if err := saveLayoutGraphic(path, out.opts, &tiling.Layout{Rows: N, Cols: N}, squares); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readLayout(path string) (*tiling.Layout, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return tiling.ParseLayout(file)
+}
+
+// getGridSizeFromUser reads "N" for a square board or "N M" for a
+// rectangle with N rows and M columns.
+func getGridSizeFromUser() (int, int, error) {
+	var N, M int
+	fmt.Print("Enter N (or N M): ")
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	n, _ := fmt.Sscan(line, &N, &M)
+	if n == 0 {
+		return 0, 0, fmt.Errorf("expected the board size N or N M, got %q", strings.TrimSpace(line))
+	}
+	if n < 2 {
+		M = N
+	}
+	if N < 1 || M < 1 {
+		return 0, 0, fmt.Errorf("invalid board size %dx%d, both sides must be positive", N, M)
+	}
+	return N, M, nil
 }
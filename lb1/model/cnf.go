@@ -0,0 +1,254 @@
+package model
+
+import (
+	"awesomeProject2/tiling"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pairwiseLimit is the largest group that gets a pairwise at-most-one
+// encoding, larger ones get a sequential counter.
+const pairwiseLimit = 6
+
+// CNF is a formula in conjunctive normal form over the variables 1 to
+// Vars. A negative literal is a negated variable.
+type CNF struct {
+	Vars    int
+	Clauses [][]int
+	// Squares maps the variables that stand for a square to it. DIMACS
+	// files keep it in "c square" comment lines.
+	Squares map[int]tiling.Square
+	Comment string
+}
+
+// CNF encodes the model. Every free cell gets a clause saying that one of
+// its squares is placed and an at-most-one constraint, and all squares
+// together get an at-most-k constraint. Both are sequential counters
+// (Sinz, 2005), which add auxiliary variables after the square ones.
+func (m *Model) CNF() *CNF {
+	f := &CNF{Vars: len(m.Squares), Squares: map[int]tiling.Square{}, Comment: m.describe()}
+	for i, square := range m.Squares {
+		f.Squares[i+1] = square
+	}
+	for _, vars := range m.cells {
+		f.Clauses = append(f.Clauses, append([]int{}, vars...))
+		f.atMost(vars, 1)
+	}
+	all := make([]int, len(m.Squares))
+	for i := range all {
+		all[i] = i + 1
+	}
+	f.atMost(all, m.limit())
+	return f
+}
+
+// atMost adds clauses that allow at most k of vars to be true.
+func (f *CNF) atMost(vars []int, k int) {
+	n := len(vars)
+	switch {
+	case k >= n:
+		return
+	case k == 0:
+		for _, v := range vars {
+			f.Clauses = append(f.Clauses, []int{-v})
+		}
+		return
+	case k == 1 && n <= pairwiseLimit:
+		for i := range vars {
+			for j := i + 1; j < n; j++ {
+				f.Clauses = append(f.Clauses, []int{-vars[i], -vars[j]})
+			}
+		}
+		return
+	}
+
+	// s(i, j) is true when at least j+1 of the first i+1 variables are.
+	first := f.Vars + 1
+	f.Vars += (n - 1) * k
+	s := func(i, j int) int { return first + i*k + j }
+
+	f.Clauses = append(f.Clauses, []int{-vars[0], s(0, 0)})
+	for j := 1; j < k; j++ {
+		f.Clauses = append(f.Clauses, []int{-s(0, j)})
+	}
+	for i := 1; i < n-1; i++ {
+		f.Clauses = append(f.Clauses,
+			[]int{-vars[i], s(i, 0)},
+			[]int{-s(i-1, 0), s(i, 0)})
+		for j := 1; j < k; j++ {
+			f.Clauses = append(f.Clauses,
+				[]int{-vars[i], -s(i-1, j-1), s(i, j)},
+				[]int{-s(i-1, j), s(i, j)})
+		}
+		f.Clauses = append(f.Clauses, []int{-vars[i], -s(i-1, k-1)})
+	}
+	f.Clauses = append(f.Clauses, []int{-vars[n-1], -s(n-2, k-1)})
+}
+
+// WriteDIMACS writes f in the DIMACS CNF format.
+func (f *CNF) WriteDIMACS(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if f.Comment != "" {
+		fmt.Fprintf(bw, "c %s\n", f.Comment)
+	}
+	vars := make([]int, 0, len(f.Squares))
+	for v := range f.Squares {
+		vars = append(vars, v)
+	}
+	sort.Ints(vars)
+	for _, v := range vars {
+		fmt.Fprintf(bw, "c square %d %s\n", v, f.Squares[v])
+	}
+	fmt.Fprintf(bw, "p cnf %d %d\n", f.Vars, len(f.Clauses))
+	for _, clause := range f.Clauses {
+		for _, lit := range clause {
+			bw.WriteString(strconv.Itoa(lit))
+			bw.WriteByte(' ')
+		}
+		bw.WriteString("0\n")
+	}
+	return bw.Flush()
+}
+
+// ReadDIMACS reads a formula in the DIMACS CNF format, together with the
+// "c square" lines written by WriteDIMACS.
+func ReadDIMACS(r io.Reader) (*CNF, error) {
+	f := &CNF{Squares: map[int]tiling.Square{}}
+	clauses := -1
+	var clause []int
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 0:
+			continue
+		case fields[0] == "c":
+			if len(fields) == 6 && fields[1] == "square" {
+				var v int
+				var square tiling.Square
+				if _, err := fmt.Sscan(strings.Join(fields[2:], " "), &v, &square.X, &square.Y, &square.Size); err != nil {
+					return nil, fmt.Errorf("line %d: %v", lineNo, err)
+				}
+				square.X--
+				square.Y--
+				f.Squares[v] = square
+			}
+			continue
+		case fields[0] == "p":
+			if len(fields) != 4 || fields[1] != "cnf" {
+				return nil, fmt.Errorf("line %d: expected \"p cnf VARS CLAUSES\"", lineNo)
+			}
+			var err error
+			if f.Vars, err = strconv.Atoi(fields[2]); err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			if clauses, err = strconv.Atoi(fields[3]); err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			continue
+		}
+		if clauses < 0 {
+			return nil, fmt.Errorf("line %d: clause before the problem line", lineNo)
+		}
+		for _, field := range fields {
+			lit, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			if lit > f.Vars || -lit > f.Vars {
+				return nil, fmt.Errorf("line %d: literal %d out of range", lineNo, lit)
+			}
+			if lit == 0 {
+				f.Clauses = append(f.Clauses, clause)
+				clause = nil
+			} else {
+				clause = append(clause, lit)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if clauses < 0 {
+		return nil, errors.New("missing problem line")
+	}
+	if len(clause) > 0 {
+		return nil, errors.New("last clause is not terminated by 0")
+	}
+	if len(f.Clauses) != clauses {
+		return nil, fmt.Errorf("expected %d clauses, got %d", clauses, len(f.Clauses))
+	}
+	return f, nil
+}
+
+// ErrUnsatisfiable is returned for the answer of a solver that found the
+// formula unsatisfiable.
+var ErrUnsatisfiable = errors.New("formula is unsatisfiable")
+
+// ReadAssignment reads the answer of a SAT solver for a formula over vars
+// variables and returns the value of every variable, indexed from 1. It
+// accepts the competition format ("s SATISFIABLE" and "v" lines), the
+// MiniSat format ("SAT" and a line of literals) and bare literals.
+// Variables that are not mentioned are false.
+func ReadAssignment(r io.Reader, vars int) ([]bool, error) {
+	values := make([]bool, vars+1)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "c", "SAT", "SATISFIABLE":
+			continue
+		case "UNSAT", "UNSATISFIABLE":
+			return nil, ErrUnsatisfiable
+		case "s":
+			if len(fields) > 1 && fields[1] == "UNSATISFIABLE" {
+				return nil, ErrUnsatisfiable
+			}
+			continue
+		case "v":
+			fields = fields[1:]
+		}
+		for _, field := range fields {
+			lit, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			if lit > vars || -lit > vars {
+				return nil, fmt.Errorf("line %d: literal %d out of range", lineNo, lit)
+			}
+			if lit > 0 {
+				values[lit] = true
+			}
+		}
+	}
+	return values, scanner.Err()
+}
+
+// Decode returns the fixed squares followed by the squares whose variables
+// are true in values, in variable order. The result still has to be
+// checked with tiling.VerifyLayout, an assignment from outside may well
+// be wrong.
+func (f *CNF) Decode(values []bool, fixed []tiling.Square) []tiling.Square {
+	squares := append([]tiling.Square{}, fixed...)
+	vars := make([]int, 0, len(f.Squares))
+	for v := range f.Squares {
+		if v < len(values) && values[v] {
+			vars = append(vars, v)
+		}
+	}
+	sort.Ints(vars)
+	for _, v := range vars {
+		squares = append(squares, f.Squares[v])
+	}
+	return squares
+}
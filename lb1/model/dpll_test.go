@@ -0,0 +1,147 @@
+package model
+
+// dpll is a small DPLL solver with two watched literals per clause. It
+// lets the tests check exports of small boards without an external solver
+// and is not meant for hard instances.
+type dpll struct {
+	clauses [][]int
+	// watches lists, for every literal, the clauses watching it.
+	watches map[int][]int
+	value   []int8
+	trail   []int
+}
+
+func (s *dpll) valueOf(lit int) int8 {
+	if lit > 0 {
+		return s.value[lit]
+	}
+	return -s.value[-lit]
+}
+
+func (s *dpll) assign(lit int) {
+	if lit > 0 {
+		s.value[lit] = 1
+	} else {
+		s.value[-lit] = -1
+	}
+	s.trail = append(s.trail, lit)
+}
+
+func (s *dpll) undo(to int) {
+	for _, lit := range s.trail[to:] {
+		if lit < 0 {
+			lit = -lit
+		}
+		s.value[lit] = 0
+	}
+	s.trail = s.trail[:to]
+}
+
+// propagate assigns the literals forced by the trail from position from on
+// and reports whether that went without a conflict.
+func (s *dpll) propagate(from int) bool {
+	for ; from < len(s.trail); from++ {
+		falsified := -s.trail[from]
+		watching := s.watches[falsified]
+		kept := watching[:0]
+		conflict := false
+		for i, c := range watching {
+			if conflict {
+				kept = append(kept, watching[i:]...)
+				break
+			}
+			clause := s.clauses[c]
+			if clause[0] == falsified {
+				clause[0], clause[1] = clause[1], clause[0]
+			}
+			if s.valueOf(clause[0]) == 1 {
+				kept = append(kept, c)
+				continue
+			}
+			moved := false
+			for k := 2; k < len(clause); k++ {
+				if s.valueOf(clause[k]) != -1 {
+					clause[1], clause[k] = clause[k], clause[1]
+					s.watches[clause[1]] = append(s.watches[clause[1]], c)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+			kept = append(kept, c)
+			switch s.valueOf(clause[0]) {
+			case -1:
+				conflict = true
+			case 0:
+				s.assign(clause[0])
+			}
+		}
+		s.watches[falsified] = kept
+		if conflict {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *dpll) search(from int) bool {
+	if !s.propagate(from) {
+		return false
+	}
+	v := 1
+	for v < len(s.value) && s.value[v] != 0 {
+		v++
+	}
+	if v == len(s.value) {
+		return true
+	}
+	mark := len(s.trail)
+	for _, lit := range []int{v, -v} {
+		s.assign(lit)
+		if s.search(mark) {
+			return true
+		}
+		s.undo(mark)
+	}
+	return false
+}
+
+// solveDPLL decides f with the DPLL solver. It returns the value of
+// every variable, indexed from 1, and whether f is satisfiable. Branching
+// tries variables in order, true first, so with a model the squares are
+// decided before the auxiliary variables.
+func solveDPLL(f *CNF) ([]bool, bool) {
+	s := &dpll{watches: map[int][]int{}, value: make([]int8, f.Vars+1)}
+	var units []int
+	for _, clause := range f.Clauses {
+		switch len(clause) {
+		case 0:
+			return nil, false
+		case 1:
+			units = append(units, clause[0])
+		default:
+			c := len(s.clauses)
+			s.clauses = append(s.clauses, append([]int{}, clause...))
+			s.watches[clause[0]] = append(s.watches[clause[0]], c)
+			s.watches[clause[1]] = append(s.watches[clause[1]], c)
+		}
+	}
+	for _, lit := range units {
+		switch s.valueOf(lit) {
+		case -1:
+			return nil, false
+		case 0:
+			s.assign(lit)
+		}
+	}
+	if !s.search(0) {
+		return nil, false
+	}
+	values := make([]bool, f.Vars+1)
+	for v := 1; v <= f.Vars; v++ {
+		values[v] = s.value[v] == 1
+	}
+	return values, true
+}
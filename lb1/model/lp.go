@@ -0,0 +1,67 @@
+package model
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// termsPerLine keeps the lines of an LP file well below the 510 characters
+// CPLEX reads.
+const termsPerLine = 8
+
+// name is the LP variable of a square, with 1-based coordinates.
+func (m *Model) name(v int) string {
+	square := m.Squares[v-1]
+	return fmt.Sprintf("s_%d_%d_%d", square.X+1, square.Y+1, square.Size)
+}
+
+// writeSum writes the sum of the variables vars, wrapped over lines.
+func (m *Model) writeSum(w *bufio.Writer, vars []int) {
+	for i, v := range vars {
+		switch {
+		case i == 0:
+		case i%termsPerLine == 0:
+			w.WriteString("\n   + ")
+		default:
+			w.WriteString(" + ")
+		}
+		w.WriteString(m.name(v))
+	}
+}
+
+// WriteLP writes the model as a 0-1 program in CPLEX LP format. Every free
+// cell is covered exactly once, the placed squares are at most k and their
+// number is minimized, so a MIP solver also finds the optimum below k.
+func (m *Model) WriteLP(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "\\ %s\n", m.describe())
+	all := make([]int, len(m.Squares))
+	for i := range all {
+		all[i] = i + 1
+	}
+
+	bw.WriteString("Minimize\n squares: ")
+	m.writeSum(bw, all)
+	bw.WriteString("\nSubject To\n")
+	for c, vars := range m.cells {
+		fmt.Fprintf(bw, " cell_%d_%d: ", m.names[c][0], m.names[c][1])
+		m.writeSum(bw, vars)
+		bw.WriteString(" = 1\n")
+	}
+	bw.WriteString(" limit: ")
+	m.writeSum(bw, all)
+	fmt.Fprintf(bw, " <= %d\n", m.limit())
+
+	bw.WriteString("Binary\n")
+	names := make([]string, len(all))
+	for i, v := range all {
+		names[i] = m.name(v)
+	}
+	for i := 0; i < len(names); i += termsPerLine {
+		fmt.Fprintf(bw, " %s\n", strings.Join(names[i:min(i+termsPerLine, len(names))], " "))
+	}
+	bw.WriteString("End\n")
+	return bw.Flush()
+}
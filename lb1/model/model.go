@@ -0,0 +1,122 @@
+// Package model states "tile the free cells of a board with at most k
+// squares" as a SAT formula in DIMACS CNF or as a 0-1 program in CPLEX LP
+// format, so that the problem can be handed to an external solver, and
+// turns a satisfying assignment back into squares.
+package model
+
+import (
+	"awesomeProject2/tiling"
+	"fmt"
+)
+
+// Model is the problem of tiling a layout with at most K squares, its
+// fixed squares included. Variable i+1 is true when Squares[i] is placed.
+type Model struct {
+	Layout  *tiling.Layout
+	K       int
+	Squares []tiling.Square
+	// cells holds, for every free cell in row-major order, the variables
+	// of the squares that cover it.
+	cells [][]int
+	// names holds the coordinates of the free cells, 1-based.
+	names [][2]int
+}
+
+// New lists every square the size rule allows on the free cells of l. As
+// for the solver, a plain square board must not be covered by a single
+// square.
+func New(l *tiling.Layout, k int, rule tiling.SizeRule) (*Model, error) {
+	if l.Rows <= 0 || l.Cols <= 0 {
+		return nil, fmt.Errorf("invalid board size %dx%d", l.Rows, l.Cols)
+	}
+	if k < len(l.Fixed) {
+		return nil, fmt.Errorf("k=%d is below the %d fixed squares", k, len(l.Fixed))
+	}
+
+	taken := make([][]bool, l.Rows)
+	for i := range taken {
+		taken[i] = make([]bool, l.Cols)
+		if i < len(l.Blocked) {
+			copy(taken[i], l.Blocked[i])
+		}
+	}
+	for _, square := range l.Fixed {
+		for i := square.X; i < square.X+square.Size; i++ {
+			for j := square.Y; j < square.Y+square.Size; j++ {
+				if i < 0 || j < 0 || i >= l.Rows || j >= l.Cols || taken[i][j] {
+					return nil, fmt.Errorf("fixed square %s is outside the board or overlaps", square)
+				}
+				taken[i][j] = true
+			}
+		}
+	}
+
+	maxSide := min(l.Rows, l.Cols)
+	if l.Rows == l.Cols && l.Plain() {
+		maxSide--
+	}
+	m := &Model{Layout: l, K: k}
+	cellIndex := make([][]int, l.Rows)
+	for i := range cellIndex {
+		cellIndex[i] = make([]int, l.Cols)
+		for j := range cellIndex[i] {
+			cellIndex[i][j] = -1
+			if !taken[i][j] {
+				cellIndex[i][j] = len(m.cells)
+				m.cells = append(m.cells, nil)
+				m.names = append(m.names, [2]int{i + 1, j + 1})
+			}
+		}
+	}
+
+	for x := 0; x < l.Rows; x++ {
+		for y := 0; y < l.Cols; y++ {
+			for size := 1; size <= maxSide && x+size <= l.Rows && y+size <= l.Cols; size++ {
+				if !free(taken, x, y, size) {
+					break
+				}
+				if !rule.Allows(size) {
+					continue
+				}
+				m.Squares = append(m.Squares, tiling.Square{X: x, Y: y, Size: size})
+				v := len(m.Squares)
+				for i := x; i < x+size; i++ {
+					for j := y; j < y+size; j++ {
+						c := cellIndex[i][j]
+						m.cells[c] = append(m.cells[c], v)
+					}
+				}
+			}
+		}
+	}
+	for c, vars := range m.cells {
+		if len(vars) == 0 {
+			return nil, fmt.Errorf("cell (%d, %d) cannot be covered by an allowed square", m.names[c][0], m.names[c][1])
+		}
+	}
+	return m, nil
+}
+
+// free reports whether the size×size square at (x, y) covers no taken
+// cell.
+func free(taken [][]bool, x, y, size int) bool {
+	for i := x; i < x+size; i++ {
+		for j := y; j < y+size; j++ {
+			if taken[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// limit is the number of squares the free cells may take.
+func (m *Model) limit() int {
+	return m.K - len(m.Layout.Fixed)
+}
+
+// describe is the comment line that heads both file formats.
+func (m *Model) describe() string {
+	return fmt.Sprintf("tiling of a %dx%d board with at most %d squares, %d fixed",
+		m.Layout.Rows, m.Layout.Cols, m.K, len(m.Layout.Fixed))
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"awesomeProject2/tiling"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// obstacleLayout has blocked cells and a fixed square.
+const obstacleLayout = `4 5
+#....
+.....
+.....
+....#
+1 4 2
+`
+
+// The CNF of a board goes through the DIMACS writer and reader and the
+// DPLL solver. With k at the optimum the decoded tiling must be valid, one
+// square less must be unsatisfiable.
+func TestCNFRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string
+		rule   tiling.SizeRule
+		opt    int
+	}{
+		{"2x2", "2 2\n..\n..\n", tiling.SizeRule{}, 4},
+		{"3x3", "3 3\n...\n...\n...\n", tiling.SizeRule{}, 6},
+		{"obstacles", obstacleLayout, tiling.SizeRule{}, 6},
+		{"allowed", "4 6\n......\n......\n......\n......\n", tiling.SizeRule{Allowed: []int{2}}, 6},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			layout, err := tiling.ParseLayout(strings.NewReader(tc.layout))
+			if err != nil {
+				t.Fatal(err)
+			}
+			opt := tc.opt
+			f := roundTrip(t, layout, opt, tc.rule)
+			values, ok := solveDPLL(f)
+			if !ok {
+				t.Fatalf("k=%d: unsatisfiable", opt)
+			}
+			tiled := f.Decode(values, layout.Fixed)
+			if err := tiling.VerifyLayout(layout, tiled); err != nil {
+				t.Errorf("k=%d: %v", opt, err)
+			}
+			if len(tiled) > opt {
+				t.Errorf("k=%d: decoded %d squares", opt, len(tiled))
+			}
+			for _, square := range tiled {
+				if !tc.rule.Allows(square.Size) {
+					t.Errorf("k=%d: square %s breaks the size rule", opt, square)
+				}
+			}
+
+			if _, ok := solveDPLL(roundTrip(t, layout, opt-1, tc.rule)); ok {
+				t.Errorf("k=%d: satisfiable below the optimum", opt-1)
+			}
+		})
+	}
+}
+
+// roundTrip writes the CNF of the layout with at most k squares of the
+// rule as DIMACS and reads it back.
+func roundTrip(t *testing.T, layout *tiling.Layout, k int, rule tiling.SizeRule) *CNF {
+	t.Helper()
+	m, err := New(layout, k, rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := m.CNF().WriteDIMACS(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := ReadDIMACS(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
@@ -1,86 +1,307 @@
 package main
 
 import (
+	"awesomeProject2/tiling"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/plotutil"
 	"gonum.org/v1/plot/vg"
-	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
 )
 
-func Benchmark() {
-	var data []struct {
-		N          int
-		Iterations int
+// BenchmarkConfig selects the boards a benchmark solves and where its
+// results go.
+type BenchmarkConfig struct {
+	// From and To bound the range of N, both included.
+	From, To int
+	// Filter keeps "primes", "composites" or "all" N of the range.
+	Filter string
+	// Workers is used by the parallel runs, all CPUs when below 2.
+	Workers int
+	// OutDir receives results.csv, results.json and the plots.
+	OutDir string
+	// LogX and LogY switch the axes of the plots to a log scale.
+	LogX, LogY bool
+	// Unpruned adds a run without pruning, which shows what the pruning
+	// saves but takes far longer from N=20 on.
+	Unpruned bool
+	// DLX adds a run of the exact cover engine, which is much slower on
+	// the larger boards.
+	DLX bool
+}
+
+func DefaultBenchmarkConfig() BenchmarkConfig {
+	return BenchmarkConfig{From: 2, To: 50, Filter: "primes", OutDir: "./lb1/images"}
+}
+
+// BenchmarkResult is one solve of the benchmark.
+type BenchmarkResult struct {
+	N          int           `json:"n"`
+	Mode       string        `json:"mode"`
+	Workers    int           `json:"workers"`
+	MinSquares int           `json:"min_squares"`
+	Iterations int           `json:"iterations"`
+	WallTime   time.Duration `json:"wall_time_ns"`
+	Allocs     uint64        `json:"allocs"`
+	AllocBytes uint64        `json:"alloc_bytes"`
+}
+
+// modes returns the solver setups cfg runs for every N, in plot order. The
+// sequential run always comes first.
+func (c BenchmarkConfig) modes() []string {
+	modes := []string{"sequential", "parallel"}
+	if c.Unpruned {
+		modes = append(modes, "unpruned")
 	}
+	if c.DLX {
+		modes = append(modes, "dlx")
+	}
+	return modes
+}
 
-	for N := 2; N <= 40; N++ {
-		if !IsPrime(N) {
-			continue
+func (c BenchmarkConfig) includes(N int) (bool, error) {
+	switch c.Filter {
+	case "primes":
+		return IsPrime(N), nil
+	case "composites":
+		return N > 1 && !IsPrime(N), nil
+	case "all":
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown benchmark filter %q, expected primes, composites or all", c.Filter)
+}
+
+// Benchmark solves every selected N with pruning, with pruning on several
+// workers and, if asked for, without pruning and with the exact cover
+// engine. It checks that the runs agree, then writes the measurements as
+// CSV and JSON and plots them. N without a tiling, such as 1, are skipped.
+func Benchmark(cfg BenchmarkConfig) error {
+	if cfg.Workers < 2 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.From < 1 || cfg.To < cfg.From {
+		return fmt.Errorf("invalid benchmark range %d..%d", cfg.From, cfg.To)
+	}
+	if err := os.MkdirAll(cfg.OutDir, 0o755); err != nil {
+		return err
+	}
+
+	var results []BenchmarkResult
+	for N := cfg.From; N <= cfg.To; N++ {
+		ok, err := cfg.includes(N)
+		if err != nil {
+			return err
 		}
-		/**
-		Update variables for each N
-		*/
-		iterationsCnt = 0
-		minSquares = 999999
-		bestResult = []Square{}
-
-		occupied := make([][]bool, N)
-		for i := range occupied {
-			occupied[i] = make([]bool, N)
+		if !ok {
+			continue
 		}
-		newGridSize, squareSize := ScaleSize(N)
-		if newGridSize != N {
-			occupied := make([][]bool, newGridSize)
-			for i := range occupied {
-				occupied[i] = make([]bool, newGridSize)
+
+		modes := cfg.modes()
+		runs := make([]BenchmarkResult, len(modes))
+		for i, mode := range modes {
+			runs[i], err = benchmarkRun(N, mode, cfg.Workers)
+			if errors.Is(err, tiling.ErrInfeasible) {
+				fmt.Printf("Skipped N=%d, no tiling by smaller squares exists\n", N)
+				break
+			}
+			if err != nil {
+				return err
 			}
-			Solve(occupied, []Square{}, newGridSize, squareSize, 0)
-		} else {
-			initialSquare := placeInitialSquares(N, occupied)
-			Solve(occupied, initialSquare, N, 1, 0)
+			if runs[i].MinSquares != runs[0].MinSquares {
+				return fmt.Errorf("N=%d: results differ: %s %d, %s %d",
+					N, runs[0].Mode, runs[0].MinSquares, mode, runs[i].MinSquares)
+			}
+		}
+		if errors.Is(err, tiling.ErrInfeasible) {
+			continue
 		}
+		results = append(results, runs...)
 
-		data = append(data, struct {
-			N          int
-			Iterations int
-		}{N: N, Iterations: iterationsCnt})
+		sequential, parallel := runs[0], runs[1]
+		fmt.Printf("Processed N=%d, Squares=%d, Iterations=%d, Iterations/s=%.0f, Allocs=%d, Sequential=%v, Parallel(%d)=%v, Speedup=%.2f\n",
+			N, sequential.MinSquares, sequential.Iterations,
+			float64(sequential.Iterations)/sequential.WallTime.Seconds(), sequential.Allocs,
+			sequential.WallTime, cfg.Workers, parallel.WallTime, sequential.WallTime.Seconds()/parallel.WallTime.Seconds())
+		for _, run := range runs[2:] {
+			fmt.Printf("  %s: Iterations=%d, Time=%v\n", run.Mode, run.Iterations, run.WallTime)
+		}
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no N in %d..%d matches filter %q and has a tiling", cfg.From, cfg.To, cfg.Filter)
+	}
+
+	if err := writeBenchmarkCSV(filepath.Join(cfg.OutDir, "results.csv"), results); err != nil {
+		return err
+	}
+	if err := writeBenchmarkJSON(filepath.Join(cfg.OutDir, "results.json"), results); err != nil {
+		return err
+	}
+	plots := []struct {
+		file, title, label string
+		metric             func(BenchmarkResult) float64
+	}{
+		{"iterations.png", "Growth of Iterations vs N", "Number of Iterations",
+			func(r BenchmarkResult) float64 { return float64(r.Iterations) }},
+		{"time.png", "Wall Time vs N", "Seconds",
+			func(r BenchmarkResult) float64 { return r.WallTime.Seconds() }},
+		{"allocs.png", "Allocations vs N", "Number of Allocations",
+			func(r BenchmarkResult) float64 { return float64(r.Allocs) }},
+	}
+	for _, p := range plots {
+		path := filepath.Join(cfg.OutDir, p.file)
+		if err := plotBenchmark(path, p.title, p.label, results, p.metric, cfg); err != nil {
+			return err
+		}
+	}
+	fmt.Println("Results written to", cfg.OutDir)
+	return nil
+}
 
-		fmt.Printf("Processed N=%d, Iterations=%d\n", N, iterationsCnt)
+// benchmarkRun solves N once in the given mode and measures it.
+func benchmarkRun(N int, mode string, workers int) (BenchmarkResult, error) {
+	solver := tiling.NewSolver()
+	switch mode {
+	case "unpruned":
+		solver.Pruning = false
+	case "parallel":
+		solver.Workers = workers
+	case "dlx":
+		solver.Engine = tiling.DLX
 	}
 
-	p := plot.New()
-	points := make(plotter.XYs, len(data))
-	for i, d := range data {
-		points[i].X = float64(d.N)
-		points[i].Y = float64(d.Iterations)
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	_, err := solver.Solve(context.Background(), N)
+	wallTime := time.Since(start)
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		return BenchmarkResult{}, fmt.Errorf("N=%d, %s: %w", N, mode, err)
 	}
 
-	scatter, err := plotter.NewScatter(points)
+	return BenchmarkResult{
+		N:          N,
+		Mode:       mode,
+		Workers:    solver.Workers,
+		MinSquares: solver.MinSquares,
+		Iterations: solver.Iterations,
+		WallTime:   wallTime,
+		Allocs:     after.Mallocs - before.Mallocs,
+		AllocBytes: after.TotalAlloc - before.TotalAlloc,
+	}, nil
+}
+
+func writeBenchmarkCSV(path string, results []BenchmarkResult) error {
+	file, err := os.Create(path)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	w := csv.NewWriter(file)
+	if err := w.Write([]string{"n", "mode", "workers", "min_squares", "iterations", "wall_time_ns", "allocs", "alloc_bytes"}); err != nil {
+		file.Close()
+		return err
 	}
-	scatter.GlyphStyle.Color = plotutil.Color(0)
-	scatter.GlyphStyle.Radius = vg.Length(1)
+	for _, r := range results {
+		err := w.Write([]string{
+			strconv.Itoa(r.N), r.Mode, strconv.Itoa(r.Workers), strconv.Itoa(r.MinSquares),
+			strconv.Itoa(r.Iterations), strconv.FormatInt(r.WallTime.Nanoseconds(), 10),
+			strconv.FormatUint(r.Allocs, 10), strconv.FormatUint(r.AllocBytes, 10),
+		})
+		if err != nil {
+			file.Close()
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
 
-	line, err := plotter.NewLine(points)
+func writeBenchmarkJSON(path string, results []BenchmarkResult) error {
+	file, err := os.Create(path)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	line.LineStyle.Color = plotutil.Color(1)
-	line.LineStyle.Width = vg.Points(1)
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(results); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
 
-	p.Add(scatter, line)
-	p.Legend.Add("Iterations", scatter)
+// plotBenchmark draws one line per mode of a metric against N. On a log
+// scale values below the smallest positive one are raised to it, since a
+// log axis cannot show zero.
+func plotBenchmark(path, title, label string, results []BenchmarkResult, metric func(BenchmarkResult) float64, cfg BenchmarkConfig) error {
+	p := plot.New()
+	p.Title.Text = fmt.Sprintf("%s (%s N from %d to %d)", title, cfg.Filter, cfg.From, cfg.To)
+	p.X.Label.Text = "N"
+	p.Y.Label.Text = label
+	if cfg.LogX {
+		p.X.Scale, p.X.Tick.Marker = plot.LogScale{}, plot.LogTicks{}
+	}
+	if cfg.LogY {
+		p.Y.Scale, p.Y.Tick.Marker = plot.LogScale{}, plot.LogTicks{}
+	}
+
+	floor := 0.0
+	for _, r := range results {
+		if v := metric(r); v > 0 && (floor == 0 || v < floor) {
+			floor = v
+		}
+	}
+	if floor == 0 {
+		floor = 1
+	}
+
+	for i, mode := range cfg.modes() {
+		var points plotter.XYs
+		for _, r := range results {
+			if r.Mode != mode {
+				continue
+			}
+			v := metric(r)
+			if cfg.LogY && v < floor {
+				v = floor
+			}
+			points = append(points, plotter.XY{X: float64(r.N), Y: v})
+		}
 
-	p.Title.Text = "Growth of Iterations vs N (Prime Numbers Only)"
-	p.X.Label.Text = "N (Prime Numbers)"
-	p.Y.Label.Text = "Number of Iterations"
+		scatter, err := plotter.NewScatter(points)
+		if err != nil {
+			return err
+		}
+		scatter.GlyphStyle.Color = plotutil.Color(i)
+		scatter.GlyphStyle.Radius = vg.Length(1)
 
-	if err := p.Save(8*vg.Inch, 8*vg.Inch, "./lb1/images/iterations.png"); err != nil {
-		log.Fatal(err)
+		line, err := plotter.NewLine(points)
+		if err != nil {
+			return err
+		}
+		line.LineStyle.Color = plotutil.Color(i)
+		line.LineStyle.Width = vg.Points(1)
+		line.LineStyle.Dashes = plotutil.Dashes(i)
+
+		p.Add(scatter, line)
+		p.Legend.Add(mode, line)
 	}
+
+	return p.Save(8*vg.Inch, 8*vg.Inch, path)
 }
 
 func IsPrime(n int) bool {
@@ -0,0 +1,233 @@
+package render
+
+import (
+	"awesomeProject2/tiling"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"os"
+)
+
+// AnimationOptions controls how a search is recorded into a GIF.
+type AnimationOptions struct {
+	// Every keeps one frame per this many place and remove events. New
+	// best tilings always get a frame.
+	Every int
+	// MaxFrames bounds the number of frames, the frames of new best
+	// tilings included. When it is reached the sampling interval doubles
+	// and every other sampled frame is dropped, so long searches are
+	// thinned out evenly. Once only best tilings are left, every other of
+	// them is dropped, the latest kept.
+	MaxFrames int
+	// CellSize is the side of a board cell in pixels.
+	CellSize int
+	// Delay is the time a frame is shown, in 100ths of a second. New best
+	// tilings are shown ten times longer.
+	Delay int
+}
+
+func DefaultAnimationOptions() AnimationOptions {
+	return AnimationOptions{Every: 1, MaxFrames: 500, CellSize: 20, Delay: 10}
+}
+
+// bestHold is how many times longer a new best tiling is shown.
+const bestHold = 10
+
+var animationPalette = color.Palette{
+	color.White,
+	color.Black,
+	obstacleColor,
+	color.RGBA{R: 255, G: 200, B: 0, A: 255},
+	color.RGBA{R: 230, G: 25, B: 75, A: 255},
+	color.RGBA{R: 60, G: 180, B: 75, A: 255},
+	color.RGBA{R: 0, G: 130, B: 200, A: 255},
+	color.RGBA{R: 245, G: 130, B: 48, A: 255},
+	color.RGBA{R: 145, G: 30, B: 180, A: 255},
+	color.RGBA{R: 70, G: 240, B: 240, A: 255},
+	color.RGBA{R: 240, G: 50, B: 230, A: 255},
+	color.RGBA{R: 128, G: 128, B: 0, A: 255},
+	color.RGBA{R: 0, G: 128, B: 128, A: 255},
+	color.RGBA{R: 170, G: 110, B: 40, A: 255},
+}
+
+const (
+	whiteIndex = iota
+	blackIndex
+	obstacleIndex
+	bestIndex
+	firstSquareIndex
+)
+
+// frame is a snapshot of the board at one step of the search.
+type frame struct {
+	squares []tiling.Square
+	best    bool
+}
+
+// Animation records the events of a backtracking search and turns them
+// into an animated GIF. It is a tiling.Tracer.
+type Animation struct {
+	layout  *tiling.Layout
+	opts    AnimationOptions
+	current []tiling.Square
+	events  int
+	frames  []frame
+	best    []tiling.Square
+}
+
+func NewAnimation(l *tiling.Layout, opts AnimationOptions) *Animation {
+	if opts.Every < 1 {
+		opts.Every = 1
+	}
+	if opts.MaxFrames < 2 {
+		opts.MaxFrames = 2
+	}
+	return &Animation{layout: l, opts: opts}
+}
+
+// Trace updates the recorded board with a search event.
+func (a *Animation) Trace(e tiling.Event) {
+	switch e.Kind {
+	case tiling.Placed:
+		a.current = append(a.current, e.Square)
+	case tiling.Removed:
+		for i := len(a.current) - 1; i >= 0; i-- {
+			if a.current[i] == e.Square {
+				a.current = append(a.current[:i], a.current[i+1:]...)
+				break
+			}
+		}
+	case tiling.NewBest:
+		a.best = e.Tiling
+		a.add(frame{squares: e.Tiling, best: true})
+		return
+	default:
+		return
+	}
+	a.events++
+	if a.events%a.opts.Every == 0 {
+		a.add(frame{squares: append([]tiling.Square{}, a.current...)})
+	}
+}
+
+// add appends a frame, thinning the recording first if it is full. With a
+// best tiling, one frame is kept free for the final frame of Write.
+func (a *Animation) add(f frame) {
+	limit := a.opts.MaxFrames
+	if a.best != nil {
+		limit--
+	}
+	for len(a.frames) >= limit {
+		a.thin()
+	}
+	a.frames = append(a.frames, f)
+}
+
+// thin drops every other sampled frame and doubles the sampling interval,
+// or drops every other best frame when no sampled frame is left.
+func (a *Animation) thin() {
+	onlyBest := true
+	for _, f := range a.frames {
+		if !f.best {
+			onlyBest = false
+			break
+		}
+	}
+	kept := a.frames[:0]
+	count := 0
+	for _, old := range a.frames {
+		if old.best == onlyBest {
+			count++
+			if count%2 == 1 {
+				continue
+			}
+		}
+		kept = append(kept, old)
+	}
+	a.frames = kept
+	if !onlyBest {
+		a.opts.Every *= 2
+	}
+}
+
+// Frames returns the number of frames recorded so far.
+func (a *Animation) Frames() int {
+	return len(a.frames)
+}
+
+// Save writes the animation to the GIF file at path. The GIF is encoded
+// before the file is created, so a failed encoding leaves no file behind.
+func (a *Animation) Save(path string) error {
+	var buf bytes.Buffer
+	if err := a.Write(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Write encodes the recorded frames as an animated GIF.
+func (a *Animation) Write(w io.Writer) error {
+	if a.opts.CellSize <= 0 {
+		return fmt.Errorf("cell size must be positive")
+	}
+	if a.layout.Rows < 1 || a.layout.Cols < 1 {
+		return fmt.Errorf("a %dx%d board has no cells to draw", a.layout.Rows, a.layout.Cols)
+	}
+	if len(a.frames) == 0 {
+		return fmt.Errorf("no frames recorded")
+	}
+	frames := a.frames
+	if a.best != nil {
+		// The search goes on after its last improvement, so the final
+		// frame shows the best tiling again.
+		frames = append(frames[:len(frames):len(frames)], frame{squares: a.best, best: true})
+	}
+	anim := &gif.GIF{}
+	for _, f := range frames {
+		delay := a.opts.Delay
+		if f.best {
+			delay *= bestHold
+		}
+		anim.Image = append(anim.Image, a.drawFrame(f))
+		anim.Delay = append(anim.Delay, delay)
+	}
+	// The last frame stays up until the animation restarts.
+	anim.Delay[len(anim.Delay)-1] = a.opts.Delay * bestHold
+	return gif.EncodeAll(w, anim)
+}
+
+// drawFrame paints a frame: squares are colored by size, the squares of a
+// new best tiling all in the highlight color.
+func (a *Animation) drawFrame(f frame) *image.Paletted {
+	cell := a.opts.CellSize
+	l := a.layout
+	img := image.NewPaletted(image.Rect(0, 0, l.Cols*cell, l.Rows*cell), animationPalette)
+
+	for i, row := range l.Blocked {
+		for j, blocked := range row {
+			if blocked {
+				a.fill(img, i, j, 1, obstacleIndex)
+			}
+		}
+	}
+	for _, square := range f.squares {
+		index := uint8(firstSquareIndex + (square.Size-1)%(len(animationPalette)-firstSquareIndex))
+		if f.best {
+			index = bestIndex
+		}
+		a.fill(img, square.X, square.Y, square.Size, index)
+	}
+	return img
+}
+
+// fill paints the size×size block at row x, column y with a black border.
+func (a *Animation) fill(img *image.Paletted, x, y, size int, index uint8) {
+	cell := a.opts.CellSize
+	rect := image.Rect(y*cell, x*cell, (y+size)*cell, (x+size)*cell)
+	draw.Draw(img, rect, &image.Uniform{C: color.Black}, image.Point{}, draw.Src)
+	draw.Draw(img, rect.Inset(1), &image.Uniform{C: animationPalette[index]}, image.Point{}, draw.Src)
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"awesomeProject2/tiling"
+	"bytes"
+	"context"
+	"image/gif"
+	"testing"
+)
+
+// recordSolve records the search for a minimum tiling of the N×N board.
+func recordSolve(t *testing.T, n int, opts AnimationOptions) *Animation {
+	t.Helper()
+	animation := NewAnimation(&tiling.Layout{Rows: n, Cols: n}, opts)
+	solver := tiling.NewSolver()
+	solver.Tracer = animation
+	if _, err := solver.Solve(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	return animation
+}
+
+func TestAnimationWrite(t *testing.T) {
+	opts := DefaultAnimationOptions()
+	opts.CellSize = 4
+	animation := recordSolve(t, 7, opts)
+
+	var buf bytes.Buffer
+	if err := animation.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("decoding the GIF: %v", err)
+	}
+	// Write adds a final frame with the best tiling.
+	if len(anim.Image) != animation.Frames()+1 {
+		t.Errorf("got %d images, want %d", len(anim.Image), animation.Frames()+1)
+	}
+	if size := anim.Image[0].Bounds().Size(); size.X != 28 || size.Y != 28 {
+		t.Errorf("got a %v frame, want 28x28", size)
+	}
+}
+
+func TestAnimationMaxFrames(t *testing.T) {
+	for _, maxFrames := range []int{2, 3, 10} {
+		opts := DefaultAnimationOptions()
+		opts.MaxFrames, opts.CellSize = maxFrames, 2
+		animation := recordSolve(t, 13, opts)
+
+		var buf bytes.Buffer
+		if err := animation.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		anim, err := gif.DecodeAll(&buf)
+		if err != nil {
+			t.Fatalf("decoding the GIF: %v", err)
+		}
+		if len(anim.Image) > maxFrames {
+			t.Errorf("MaxFrames %d: got %d images", maxFrames, len(anim.Image))
+		}
+	}
+
+	// A recording made of new best tilings only is bounded as well.
+	opts := DefaultAnimationOptions()
+	opts.MaxFrames = 4
+	animation := NewAnimation(&tiling.Layout{Rows: 2, Cols: 2}, opts)
+	for i := 0; i < 20; i++ {
+		animation.Trace(tiling.Event{Kind: tiling.NewBest, Tiling: []tiling.Square{{X: 0, Y: 0, Size: 1}}})
+	}
+	if animation.Frames() >= opts.MaxFrames {
+		t.Errorf("got %d frames of best tilings, want fewer than %d", animation.Frames(), opts.MaxFrames)
+	}
+}
@@ -0,0 +1,221 @@
+// Package render draws tilings as PNG, SVG or PDF images through the
+// gonum vg backends.
+package render
+
+import (
+	"awesomeProject2/tiling"
+	"bytes"
+	"fmt"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+	"gonum.org/v1/plot/vg/vgpdf"
+	"gonum.org/v1/plot/vg/vgsvg"
+	"image/color"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type Format int
+
+const (
+	PNG Format = iota
+	SVG
+	PDF
+)
+
+var formatNames = []string{"png", "svg", "pdf"}
+
+func (f Format) String() string {
+	if int(f) < len(formatNames) {
+		return formatNames[f]
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+func ParseFormat(name string) (Format, error) {
+	name = strings.ToLower(strings.TrimPrefix(name, "."))
+	for i, formatName := range formatNames {
+		if formatName == name {
+			return Format(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown image format %q", name)
+}
+
+// FormatFromPath picks the format from the extension of path.
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(filepath.Ext(path))
+}
+
+// Options controls how a tiling is drawn.
+type Options struct {
+	Format Format
+	// CellSize is the side of a board cell, in pixels for PNG and in
+	// points for SVG and PDF.
+	CellSize int
+	// Palette colors the squares in order, cycling when there are more
+	// squares than colors. When it is empty the colors are drawn from a
+	// random source seeded with Seed.
+	Palette []color.Color
+	Seed    int64
+	// Labels writes the size of every square in its center.
+	Labels bool
+	// Grid draws the lines between board cells under the squares.
+	Grid bool
+}
+
+func DefaultOptions() Options {
+	return Options{Format: PNG, CellSize: 50, Seed: 1, Grid: true}
+}
+
+// ParsePalette reads a comma separated list of "#rrggbb" colors.
+func ParsePalette(list string) ([]color.Color, error) {
+	var palette []color.Color
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimPrefix(strings.TrimSpace(field), "#")
+		if field == "" {
+			continue
+		}
+		rgb, err := strconv.ParseUint(field, 16, 32)
+		if err != nil || len(field) != 6 {
+			return nil, fmt.Errorf("invalid color %q, expected #rrggbb", field)
+		}
+		palette = append(palette, color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 255})
+	}
+	return palette, nil
+}
+
+var obstacleColor = color.RGBA{R: 64, G: 64, B: 64, A: 255}
+
+// Save draws the squares over the layout into the file at path. The image
+// is rendered before the file is created, so a failed render leaves no
+// file behind.
+func Save(path string, l *tiling.Layout, squares []tiling.Square, opts Options) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, l, squares, opts); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Write draws the squares over the layout and encodes the image to w.
+// Square.X is the row of a square, so it runs down the image.
+func Write(w io.Writer, l *tiling.Layout, squares []tiling.Square, opts Options) error {
+	if opts.CellSize <= 0 {
+		return fmt.Errorf("cell size must be positive")
+	}
+	if l.Rows < 1 || l.Cols < 1 {
+		return fmt.Errorf("a %dx%d board has no cells to draw", l.Rows, l.Cols)
+	}
+	cell := vg.Length(opts.CellSize)
+	width, height := cell*vg.Length(l.Cols), cell*vg.Length(l.Rows)
+
+	var canvas vg.CanvasWriterTo
+	switch opts.Format {
+	case PNG:
+		canvas = vgimg.PngCanvas{Canvas: vgimg.NewWith(vgimg.UseWH(width, height), vgimg.UseDPI(72))}
+	case SVG:
+		canvas = vgsvg.New(width, height)
+	case PDF:
+		canvas = vgpdf.New(width, height)
+	default:
+		return fmt.Errorf("unknown image format %v", opts.Format)
+	}
+
+	c := draw.NewCanvas(canvas, width, height)
+	d := drawer{canvas: c, rows: l.Rows, cell: cell}
+	d.fill(0, 0, l.Cols, l.Rows, color.White)
+
+	if opts.Grid {
+		grid := d.lineStyle(0.5)
+		for i := 0; i <= l.Rows; i++ {
+			y := cell * vg.Length(i)
+			c.StrokeLine2(grid, 0, y, width, y)
+		}
+		for j := 0; j <= l.Cols; j++ {
+			x := cell * vg.Length(j)
+			c.StrokeLine2(grid, x, 0, x, height)
+		}
+	}
+
+	for i, row := range l.Blocked {
+		for j, blocked := range row {
+			if blocked {
+				d.obstacle(i, j)
+			}
+		}
+	}
+
+	colors := rand.New(rand.NewSource(opts.Seed))
+	for k, square := range squares {
+		var col color.Color
+		if len(opts.Palette) > 0 {
+			col = opts.Palette[k%len(opts.Palette)]
+		} else {
+			col = color.RGBA{R: uint8(colors.Intn(256)), G: uint8(colors.Intn(256)), B: uint8(colors.Intn(256)), A: 255}
+		}
+		d.square(square, col, opts.Labels)
+	}
+
+	_, err := canvas.WriteTo(w)
+	return err
+}
+
+// drawer turns board coordinates into canvas ones. The canvas origin is
+// the bottom left corner, the board origin the top left one.
+type drawer struct {
+	canvas draw.Canvas
+	rows   int
+	cell   vg.Length
+}
+
+func (d drawer) point(x, y int) vg.Point {
+	return vg.Point{X: d.cell * vg.Length(y), Y: d.cell * vg.Length(d.rows-x)}
+}
+
+func (d drawer) rect(x, y, w, h int) []vg.Point {
+	return []vg.Point{d.point(x, y), d.point(x, y+w), d.point(x+h, y+w), d.point(x+h, y)}
+}
+
+func (d drawer) fill(x, y, w, h int, col color.Color) {
+	d.canvas.FillPolygon(col, d.rect(x, y, w, h))
+}
+
+func (d drawer) lineStyle(width vg.Length) draw.LineStyle {
+	return draw.LineStyle{Color: color.Black, Width: width}
+}
+
+func (d drawer) obstacle(x, y int) {
+	d.fill(x, y, 1, 1, obstacleColor)
+	cross := draw.LineStyle{Color: color.White, Width: 1}
+	d.canvas.StrokeLines(cross, []vg.Point{d.point(x, y), d.point(x+1, y+1)})
+	d.canvas.StrokeLines(cross, []vg.Point{d.point(x, y+1), d.point(x+1, y)})
+}
+
+func (d drawer) square(square tiling.Square, col color.Color, label bool) {
+	d.fill(square.X, square.Y, square.Size, square.Size, col)
+	outline := d.rect(square.X, square.Y, square.Size, square.Size)
+	d.canvas.StrokeLines(d.lineStyle(1), append(outline, outline[0]))
+
+	if label {
+		style := draw.TextStyle{
+			Color:   color.Black,
+			Font:    plot.DefaultFont,
+			XAlign:  draw.XCenter,
+			YAlign:  draw.YCenter,
+			Handler: plot.DefaultTextHandler,
+		}
+		style.Font.Size = d.cell * 0.4
+		center := vg.Point{
+			X: d.cell * (vg.Length(square.Y) + vg.Length(square.Size)/2),
+			Y: d.cell * (vg.Length(d.rows-square.X) - vg.Length(square.Size)/2),
+		}
+		d.canvas.FillText(style, center, strconv.Itoa(square.Size))
+	}
+}
@@ -0,0 +1,102 @@
+package render
+
+import (
+	"awesomeProject2/tiling"
+	"bytes"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// squares5 is a minimum tiling of the 5×5 board.
+var squares5 = []tiling.Square{
+	{X: 0, Y: 0, Size: 3}, {X: 0, Y: 3, Size: 2}, {X: 2, Y: 3, Size: 1}, {X: 2, Y: 4, Size: 1},
+	{X: 3, Y: 0, Size: 2}, {X: 3, Y: 2, Size: 1}, {X: 3, Y: 3, Size: 2}, {X: 4, Y: 2, Size: 1},
+}
+
+func TestWrite(t *testing.T) {
+	layout := &tiling.Layout{Rows: 5, Cols: 5}
+	if err := tiling.Verify(5, 5, squares5); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.CellSize, opts.Labels = 10, true
+	var buf bytes.Buffer
+	if err := Write(&buf, layout, squares5, opts); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decoding the PNG: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != 50 || size.Y != 50 {
+		t.Errorf("got a %v image, want 50x50", size)
+	}
+
+	tests := []struct {
+		format Format
+		prefix string
+	}{
+		{SVG, "<?xml"},
+		{PDF, "%PDF-"},
+	}
+	for _, tc := range tests {
+		opts.Format = tc.format
+		buf.Reset()
+		if err := Write(&buf, layout, squares5, opts); err != nil {
+			t.Fatalf("%v: %v", tc.format, err)
+		}
+		if !bytes.HasPrefix(buf.Bytes(), []byte(tc.prefix)) {
+			t.Errorf("%v: the image starts with %q", tc.format, buf.Bytes()[:min(buf.Len(), 8)])
+		}
+	}
+}
+
+func TestWriteInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout *tiling.Layout
+		opts   Options
+	}{
+		{"no cells", &tiling.Layout{Rows: 0, Cols: 5}, DefaultOptions()},
+		{"cell size", &tiling.Layout{Rows: 5, Cols: 5}, Options{CellSize: 0}},
+		{"format", &tiling.Layout{Rows: 5, Cols: 5}, Options{Format: Format(9), CellSize: 10}},
+	}
+	for _, tc := range tests {
+		path := filepath.Join(t.TempDir(), "squares.png")
+		if err := Save(path, tc.layout, squares5, tc.opts); err == nil {
+			t.Errorf("%s: no error", tc.name)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s: the failed render left a file behind", tc.name)
+		}
+	}
+}
+
+func TestParsePalette(t *testing.T) {
+	palette, err := ParsePalette("#ff0000, 00ff80,,#0000FF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []color.Color{
+		color.RGBA{R: 255, A: 255}, color.RGBA{G: 255, B: 128, A: 255}, color.RGBA{B: 255, A: 255},
+	}
+	if len(palette) != len(want) {
+		t.Fatalf("got %v, want %v", palette, want)
+	}
+	for i := range want {
+		if palette[i] != want[i] {
+			t.Errorf("color %d: got %v, want %v", i, palette[i], want[i])
+		}
+	}
+
+	for _, list := range []string{"#ff00", "#gg0000", "#ff0000ff"} {
+		if _, err := ParsePalette(list); err == nil || !strings.Contains(err.Error(), "#rrggbb") {
+			t.Errorf("%q: got %v, want an invalid color error", list, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"awesomeProject2/server"
+	"flag"
+	"fmt"
+	"net/http"
+)
+
+// runServe implements the "serve" subcommand, which answers solve
+// requests over HTTP until the process is stopped.
+func runServe(args []string) error {
+	opts := server.DefaultOptions()
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "Address to listen on")
+	fs.IntVar(&opts.MaxConcurrent, "max-concurrent", opts.MaxConcurrent, "Number of solves that may run at once")
+	fs.DurationVar(&opts.DefaultTimeout, "default-timeout", opts.DefaultTimeout, "Timeout of requests that do not set one")
+	fs.DurationVar(&opts.MaxTimeout, "max-timeout", opts.MaxTimeout, "Largest timeout a request may ask for")
+	fs.IntVar(&opts.MaxWorkers, "max-workers", opts.MaxWorkers, "Largest number of goroutines of a single solve (0 shares the CPUs among the concurrent solves)")
+	fs.IntVar(&opts.MaxQueued, "max-queued", opts.MaxQueued, "Number of jobs that may be queued or running at once")
+	fs.DurationVar(&opts.JobTTL, "job-ttl", opts.JobTTL, "How long a finished job can still be polled")
+	fs.IntVar(&opts.MaxFinished, "max-finished", opts.MaxFinished, "Largest number of finished jobs kept")
+	fs.IntVar(&opts.MaxSide, "max-side", opts.MaxSide, "Largest number of rows or columns of a board")
+	fs.IntVar(&opts.MaxDLXSide, "max-dlx-side", opts.MaxDLXSide, "Largest number of rows or columns of a board solved with the dlx engine")
+	fs.IntVar(&opts.MaxProfileEntries, "max-profiles", opts.MaxProfileEntries, "Largest transposition table of a skyline solve")
+	fs.Parse(args)
+
+	fmt.Println("Listening on", *addr)
+	return http.ListenAndServe(*addr, server.New(opts))
+}
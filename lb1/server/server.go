@@ -0,0 +1,571 @@
+// Package server exposes the tiling solver as an HTTP JSON API.
+//
+//	POST   /solve             solve a board and wait for the tiling, or
+//	                          for its image when the query has a format
+//	POST   /jobs              start a solve in the background
+//	GET    /jobs/{id}         poll the progress or the result of a job
+//	DELETE /jobs/{id}         stop a job, keeping the best tiling so far
+//	GET    /jobs/{id}/image   render the tiling of a finished job
+//
+// Requests and responses are JSON with 1-based square coordinates, as in
+// the JSON form of tiling.Square.
+package server
+
+import (
+	"awesomeProject2/render"
+	"awesomeProject2/tiling"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options bounds the work a Server accepts. A solve runs on at most
+// MaxWorkers goroutines and MaxConcurrent solves run at once. Memory is
+// bounded per engine: the backtracking and heuristic searches need little
+// beyond the board, the skyline engine keeps at most MaxProfileEntries
+// profiles and the dlx engine only takes boards up to MaxDLXSide.
+type Options struct {
+	// MaxConcurrent is the number of solves that may run at once. Further
+	// POST /solve requests are refused, further jobs wait for a slot.
+	MaxConcurrent int
+	// DefaultTimeout applies to requests without a timeout, MaxTimeout
+	// caps the timeout of every request.
+	DefaultTimeout time.Duration
+	MaxTimeout     time.Duration
+	// MaxSide is the largest number of rows or columns of a board.
+	// MaxDLXSide is the limit for the dlx engine, whose exact cover
+	// matrix grows with the fifth power of the side.
+	MaxSide    int
+	MaxDLXSide int
+	// MaxWorkers caps the goroutines of a single solve. Requests asking
+	// for more get this many. When it is 0 the CPUs are shared among the
+	// MaxConcurrent solves.
+	MaxWorkers int
+	// MaxProfileEntries caps the transposition table of a skyline solve.
+	MaxProfileEntries int
+	// MaxQueued is the number of jobs that may be queued or running at
+	// once, further POST /jobs requests are refused with 503.
+	MaxQueued int
+	// JobTTL is how long a finished job can still be polled. At most
+	// MaxFinished finished jobs are kept, the oldest are dropped first.
+	JobTTL      time.Duration
+	MaxFinished int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MaxConcurrent:     2,
+		DefaultTimeout:    10 * time.Second,
+		MaxTimeout:        time.Minute,
+		MaxSide:           64,
+		MaxDLXSide:        24,
+		MaxProfileEntries: 1 << 18,
+		MaxQueued:         16,
+		JobTTL:            10 * time.Minute,
+		MaxFinished:       100,
+	}
+}
+
+// Request describes a board to solve.
+type Request struct {
+	Rows int `json:"rows"`
+	// Cols defaults to Rows.
+	Cols int `json:"cols,omitempty"`
+	// Timeout is a Go duration such as "5s".
+	Timeout string `json:"timeout,omitempty"`
+	// Workers is capped by Options.MaxWorkers.
+	Workers int `json:"workers,omitempty"`
+	// Engine is "backtracking" (the default), "skyline", "dlx" or
+	// "heuristic".
+	Engine  string `json:"engine,omitempty"`
+	MinSize int    `json:"min_size,omitempty"`
+	MaxSize int    `json:"max_size,omitempty"`
+	Allowed []int  `json:"allowed,omitempty"`
+}
+
+// Result is the outcome of a solve. Optimal is false when the timeout
+// stopped the search, Squares is then the best tiling found.
+type Result struct {
+	Rows       int             `json:"rows"`
+	Cols       int             `json:"cols"`
+	MinSquares int             `json:"min_squares"`
+	Optimal    bool            `json:"optimal"`
+	Iterations int             `json:"iterations"`
+	ElapsedMs  int64           `json:"elapsed_ms"`
+	Squares    []tiling.Square `json:"squares"`
+}
+
+// Job states.
+const (
+	Queued  = "queued"
+	Running = "running"
+	Done    = "done"
+	Failed  = "failed"
+)
+
+// progress counts the events of a running search. The solver reports them
+// from its own goroutine while handlers read them.
+type progress struct {
+	placed atomic.Int64
+	best   atomic.Int64
+}
+
+func (p *progress) Trace(e tiling.Event) {
+	switch e.Kind {
+	case tiling.Placed:
+		p.placed.Add(1)
+	case tiling.NewBest:
+		p.best.Store(int64(len(e.Tiling)))
+	}
+}
+
+type job struct {
+	id       string
+	request  Request
+	progress progress
+	cancel   context.CancelFunc
+
+	mu       sync.Mutex
+	status   string
+	started  time.Time
+	finished time.Time
+	result   *Result
+	err      string
+}
+
+// finish records the outcome of a job, a failure when err is not empty.
+func (j *job) finish(result *Result, err string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.finished = time.Now()
+	if err != "" {
+		j.status, j.err = Failed, err
+		return
+	}
+	j.status, j.result = Done, result
+}
+
+// finishedAt returns when the job finished, zero while it is queued or
+// running.
+func (j *job) finishedAt() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.finished
+}
+
+// JobStatus is the JSON form of a job. Placed and BestSquares are only
+// reported by the sequential backtracking search.
+type JobStatus struct {
+	ID          string  `json:"id"`
+	Status      string  `json:"status"`
+	ElapsedMs   int64   `json:"elapsed_ms"`
+	Placed      int64   `json:"placed"`
+	BestSquares int64   `json:"best_squares,omitempty"`
+	Result      *Result `json:"result,omitempty"`
+	Error       string  `json:"error,omitempty"`
+}
+
+func (j *job) snapshot() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	s := JobStatus{
+		ID:          j.id,
+		Status:      j.status,
+		Placed:      j.progress.placed.Load(),
+		BestSquares: j.progress.best.Load(),
+		Result:      j.result,
+		Error:       j.err,
+	}
+	switch {
+	case j.result != nil:
+		s.ElapsedMs = j.result.ElapsedMs
+	case j.started.IsZero():
+	case !j.finished.IsZero():
+		s.ElapsedMs = j.finished.Sub(j.started).Milliseconds()
+	default:
+		s.ElapsedMs = time.Since(j.started).Milliseconds()
+	}
+	return s
+}
+
+// Server answers the API requests.
+type Server struct {
+	opts  Options
+	slots chan struct{}
+	mux   *http.ServeMux
+
+	mu     sync.Mutex
+	jobs   map[string]*job
+	nextID int
+}
+
+func New(opts Options) *Server {
+	if opts.MaxConcurrent < 1 {
+		opts.MaxConcurrent = 1
+	}
+	if opts.MaxWorkers < 1 {
+		opts.MaxWorkers = max(1, runtime.NumCPU()/opts.MaxConcurrent)
+	}
+	if opts.MaxQueued < 1 {
+		opts.MaxQueued = opts.MaxConcurrent
+	}
+	s := &Server{
+		opts:  opts,
+		slots: make(chan struct{}, opts.MaxConcurrent),
+		mux:   http.NewServeMux(),
+		jobs:  map[string]*job{},
+	}
+	s.mux.HandleFunc("POST /solve", s.handleSolve)
+	s.mux.HandleFunc("POST /jobs", s.handleCreateJob)
+	s.mux.HandleFunc("GET /jobs/{id}", s.handleGetJob)
+	s.mux.HandleFunc("DELETE /jobs/{id}", s.handleCancelJob)
+	s.mux.HandleFunc("GET /jobs/{id}/image", s.handleJobImage)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// prepare checks a request and builds its solver and timeout.
+func (s *Server) prepare(req *Request) (*tiling.Solver, time.Duration, error) {
+	if req.Cols == 0 {
+		req.Cols = req.Rows
+	}
+	if req.Rows < 1 || req.Cols < 1 || req.Rows > s.opts.MaxSide || req.Cols > s.opts.MaxSide {
+		return nil, 0, fmt.Errorf("rows and cols must be between 1 and %d", s.opts.MaxSide)
+	}
+	timeout := s.opts.DefaultTimeout
+	if req.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(req.Timeout); err != nil || timeout <= 0 {
+			return nil, 0, fmt.Errorf("invalid timeout %q", req.Timeout)
+		}
+	}
+	if s.opts.MaxTimeout > 0 && timeout > s.opts.MaxTimeout {
+		timeout = s.opts.MaxTimeout
+	}
+
+	solver := tiling.NewSolver()
+	solver.Workers = min(max(req.Workers, 1), s.opts.MaxWorkers)
+	solver.ProfileEntries = s.opts.MaxProfileEntries
+	solver.Sizes = tiling.SizeRule{Min: req.MinSize, Max: req.MaxSize, Allowed: req.Allowed}
+	if err := solver.Sizes.Validate(req.Rows, req.Cols); err != nil {
+		return nil, 0, err
+	}
+	if req.Engine != "" {
+		var err error
+		if solver.Engine, err = tiling.ParseEngine(req.Engine); err != nil {
+			return nil, 0, err
+		}
+	}
+	if solver.Engine == tiling.DLX && max(req.Rows, req.Cols) > s.opts.MaxDLXSide {
+		return nil, 0, fmt.Errorf("the dlx engine takes rows and cols up to %d", s.opts.MaxDLXSide)
+	}
+	return solver, timeout, nil
+}
+
+// errSolverPanic marks the error of a solve that panicked.
+var errSolverPanic = errors.New("solver panic")
+
+// solve runs a prepared solver. A timeout is not an error as long as some
+// tiling was found. A panic of the solver is turned into an error, so that
+// one bad request cannot take the server down. The parallel search
+// recovers the panics of its workers itself and reports them as
+// tiling.ErrWorkerPanic.
+func solve(ctx context.Context, solver *tiling.Solver, req Request) (result *Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("%w: %v", errSolverPanic, r)
+		}
+	}()
+	start := time.Now()
+	squares, err := solver.SolveRect(ctx, req.Rows, req.Cols)
+	if errors.Is(err, tiling.ErrWorkerPanic) {
+		return nil, fmt.Errorf("%w: %v", errSolverPanic, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Rows:       req.Rows,
+		Cols:       req.Cols,
+		MinSquares: solver.MinSquares,
+		Optimal:    solver.Optimal,
+		Iterations: solver.Iterations,
+		ElapsedMs:  time.Since(start).Milliseconds(),
+		Squares:    squares,
+	}, nil
+}
+
+func decodeRequest(r *http.Request) (Request, error) {
+	var req Request
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return req, fmt.Errorf("invalid request: %v", err)
+	}
+	return req, nil
+}
+
+func (s *Server) handleSolve(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	solver, timeout, err := s.prepare(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	// With a format in the query the answer is the image of the tiling.
+	// Its options are checked before the solve.
+	var opts render.Options
+	var contentType string
+	image := r.URL.Query().Has("format")
+	if image {
+		if opts, contentType, err = imageOptions(r); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	default:
+		writeError(w, http.StatusTooManyRequests, errors.New("too many solves running, retry later or start a job"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	result, err := solve(ctx, solver, req)
+	if errors.Is(err, errSolverPanic) {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if image {
+		writeImage(w, result, opts, contentType)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	solver, timeout, err := s.prepare(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.mu.Lock()
+	s.evict(time.Now())
+	if s.active() >= s.opts.MaxQueued {
+		s.mu.Unlock()
+		writeError(w, http.StatusServiceUnavailable, errors.New("job queue is full, retry later"))
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.nextID++
+	j := &job{id: strconv.Itoa(s.nextID), request: req, cancel: cancel, status: Queued}
+	s.jobs[j.id] = j
+	s.mu.Unlock()
+
+	solver.Tracer = &j.progress
+	go s.run(ctx, j, solver, timeout)
+	writeJSON(w, http.StatusAccepted, j.snapshot())
+}
+
+// run waits for a free slot and solves the board of a job. The timeout
+// starts when the job leaves the queue. Whatever goes wrong, the job ends
+// up failed rather than the server.
+func (s *Server) run(ctx context.Context, j *job, solver *tiling.Solver, timeout time.Duration) {
+	defer j.cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			j.finish(nil, fmt.Sprintf("%v: %v", errSolverPanic, r))
+		}
+	}()
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		j.finish(nil, "cancelled while queued")
+		return
+	}
+
+	j.mu.Lock()
+	j.status, j.started = Running, time.Now()
+	j.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := solve(ctx, solver, j.request)
+	if err != nil {
+		j.finish(nil, err.Error())
+		return
+	}
+	j.finish(result, "")
+}
+
+// active counts the queued and running jobs. s.mu must be held.
+func (s *Server) active() int {
+	count := 0
+	for _, j := range s.jobs {
+		if j.finishedAt().IsZero() {
+			count++
+		}
+	}
+	return count
+}
+
+// evict drops the finished jobs older than JobTTL, and then the oldest
+// finished jobs beyond MaxFinished. s.mu must be held.
+func (s *Server) evict(now time.Time) {
+	var finished []*job
+	for id, j := range s.jobs {
+		end := j.finishedAt()
+		switch {
+		case end.IsZero():
+		case s.opts.JobTTL > 0 && now.Sub(end) > s.opts.JobTTL:
+			delete(s.jobs, id)
+		default:
+			finished = append(finished, j)
+		}
+	}
+	if s.opts.MaxFinished <= 0 || len(finished) <= s.opts.MaxFinished {
+		return
+	}
+	sort.Slice(finished, func(a, b int) bool {
+		return finished[a].finishedAt().Before(finished[b].finishedAt())
+	})
+	for _, j := range finished[:len(finished)-s.opts.MaxFinished] {
+		delete(s.jobs, j.id)
+	}
+}
+
+func (s *Server) job(w http.ResponseWriter, r *http.Request) *job {
+	s.mu.Lock()
+	s.evict(time.Now())
+	j := s.jobs[r.PathValue("id")]
+	s.mu.Unlock()
+	if j == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no job %q", r.PathValue("id")))
+	}
+	return j
+}
+
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	if j := s.job(w, r); j != nil {
+		writeJSON(w, http.StatusOK, j.snapshot())
+	}
+}
+
+func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	if j := s.job(w, r); j != nil {
+		j.cancel()
+		writeJSON(w, http.StatusAccepted, j.snapshot())
+	}
+}
+
+// handleJobImage renders a finished job. The query takes format (png or
+// svg), cell, seed, labels and grid, as the options of render.
+func (s *Server) handleJobImage(w http.ResponseWriter, r *http.Request) {
+	j := s.job(w, r)
+	if j == nil {
+		return
+	}
+	status := j.snapshot()
+	if status.Result == nil {
+		writeError(w, http.StatusConflict, fmt.Errorf("job %s is %s", j.id, status.Status))
+		return
+	}
+
+	opts, contentType, err := imageOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeImage(w, status.Result, opts, contentType)
+}
+
+// writeImage renders the tiling of a result. The image goes to a buffer
+// first, so that a failed render can still be answered with an error
+// status.
+func writeImage(w http.ResponseWriter, res *Result, opts render.Options, contentType string) {
+	var buf bytes.Buffer
+	if err := render.Write(&buf, &tiling.Layout{Rows: res.Rows, Cols: res.Cols}, res.Squares, opts); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("rendering image: %w", err))
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
+
+// imageOptions reads the render options of an image request and returns
+// them with the content type of the image.
+func imageOptions(r *http.Request) (render.Options, string, error) {
+	opts := render.DefaultOptions()
+	query := r.URL.Query()
+	var err error
+	if v := query.Get("format"); v != "" {
+		if opts.Format, err = render.ParseFormat(v); err != nil {
+			return opts, "", err
+		}
+	}
+	contentTypes := map[render.Format]string{render.PNG: "image/png", render.SVG: "image/svg+xml"}
+	contentType, ok := contentTypes[opts.Format]
+	if !ok {
+		return opts, "", fmt.Errorf("format must be png or svg")
+	}
+	if v := query.Get("cell"); v != "" {
+		if opts.CellSize, err = strconv.Atoi(v); err != nil || opts.CellSize < 1 || opts.CellSize > 200 {
+			return opts, "", fmt.Errorf("cell must be between 1 and 200")
+		}
+	}
+	if v := query.Get("seed"); v != "" {
+		if opts.Seed, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return opts, "", fmt.Errorf("invalid seed %q", v)
+		}
+	}
+	if v := query.Get("labels"); v != "" {
+		if opts.Labels, err = strconv.ParseBool(v); err != nil {
+			return opts, "", fmt.Errorf("invalid labels %q", v)
+		}
+	}
+	if v := query.Get("grid"); v != "" {
+		if opts.Grid, err = strconv.ParseBool(v); err != nil {
+			return opts, "", fmt.Errorf("invalid grid %q", v)
+		}
+	}
+	return opts, contentType, nil
+}
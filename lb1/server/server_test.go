@@ -0,0 +1,338 @@
+package server
+
+import (
+	"awesomeProject2/tiling"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// hardSide is a board the solver cannot finish within the timeouts of the
+// tests, so that its solves stay running until they are stopped.
+const hardSide = 61
+
+func newTestServer(t *testing.T, opts Options) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(New(opts))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// do sends a request with an optional JSON body and decodes a JSON answer
+// into out when it is not nil.
+func do(t *testing.T, method, url, body string, out any) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decoding the answer: %v", method, url, err)
+		}
+	}
+	return resp
+}
+
+// waitJob polls a job until done returns true for its status.
+func waitJob(t *testing.T, ts *httptest.Server, id string, done func(JobStatus) bool) JobStatus {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		var status JobStatus
+		do(t, "GET", ts.URL+"/jobs/"+id, "", &status)
+		if done(status) {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is still %s", id, status.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func finished(status JobStatus) bool {
+	return status.Status == Done || status.Status == Failed
+}
+
+func createJob(t *testing.T, ts *httptest.Server, body string) JobStatus {
+	t.Helper()
+	var status JobStatus
+	if resp := do(t, "POST", ts.URL+"/jobs", body, &status); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /jobs %s: got %d", body, resp.StatusCode)
+	}
+	return status
+}
+
+func TestSolve(t *testing.T) {
+	ts := newTestServer(t, DefaultOptions())
+	var result Result
+	resp := do(t, "POST", ts.URL+"/solve", `{"rows": 7}`, &result)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got %d", resp.StatusCode)
+	}
+	if !result.Optimal || result.MinSquares != 9 {
+		t.Errorf("got %d squares, optimal %v, want 9, true", result.MinSquares, result.Optimal)
+	}
+	if err := tiling.Verify(7, 7, result.Squares); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSolveTimeout(t *testing.T) {
+	ts := newTestServer(t, DefaultOptions())
+	start := time.Now()
+	var result Result
+	resp := do(t, "POST", ts.URL+"/solve", fmt.Sprintf(`{"rows": %d, "timeout": "200ms"}`, hardSide), &result)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got %d", resp.StatusCode)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the solve took %v", elapsed)
+	}
+	if result.Optimal {
+		t.Errorf("a stopped search is reported optimal")
+	}
+	if err := tiling.Verify(hardSide, hardSide, result.Squares); err != nil {
+		t.Errorf("best tiling so far: %v", err)
+	}
+}
+
+func TestBadRequests(t *testing.T) {
+	ts := newTestServer(t, DefaultOptions())
+	for _, body := range []string{
+		`{"rows": 0}`,
+		`{"rows": 1000}`,
+		`{"rows": 5, "timeout": "soon"}`,
+		`{"rows": 5, "engine": "magic"}`,
+		`{"rows": 5, "unknown": 1}`,
+		`{"rows": 1, "min_size": 2}`,
+		`{"rows": 5, "min_size": 3, "max_size": 2}`,
+		`{"rows": 5, "allowed": [0]}`,
+		`{"rows": 61, "engine": "dlx", "max_size": 60, "timeout": "1s"}`,
+	} {
+		for _, path := range []string{"/solve", "/jobs"} {
+			if resp := do(t, "POST", ts.URL+path, body, nil); resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("POST %s %s: got %d, want 400", path, body, resp.StatusCode)
+			}
+		}
+	}
+}
+
+func TestWorkersAreCapped(t *testing.T) {
+	opts := DefaultOptions()
+	opts.MaxWorkers = 2
+	s := New(opts)
+	solver, _, err := s.prepare(&Request{Rows: 5, Workers: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if solver.Workers != 2 {
+		t.Errorf("got %d workers, want 2", solver.Workers)
+	}
+	if solver.ProfileEntries != opts.MaxProfileEntries {
+		t.Errorf("got a table of %d profiles, want %d", solver.ProfileEntries, opts.MaxProfileEntries)
+	}
+
+	// By default the concurrent solves share the CPUs.
+	opts = DefaultOptions()
+	opts.MaxConcurrent = runtime.NumCPU() + 1
+	solver, _, err = New(opts).prepare(&Request{Rows: 5, Workers: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if solver.Workers != 1 {
+		t.Errorf("got %d workers with more solves than CPUs, want 1", solver.Workers)
+	}
+}
+
+func TestJobImage(t *testing.T) {
+	ts := newTestServer(t, DefaultOptions())
+	job := createJob(t, ts, `{"rows": 5, "cols": 7}`)
+	status := waitJob(t, ts, job.ID, finished)
+	if status.Status != Done || status.Result.MinSquares != 5 {
+		t.Fatalf("got %+v", status)
+	}
+
+	tests := []struct {
+		query, contentType, prefix string
+	}{
+		{"", "image/png", "\x89PNG"},
+		{"?format=png&cell=10&labels=true", "image/png", "\x89PNG"},
+		{"?format=svg&grid=false", "image/svg+xml", "<?xml"},
+	}
+	for _, tc := range tests {
+		resp, err := http.Get(ts.URL + "/jobs/" + job.ID + "/image" + tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != tc.contentType {
+			t.Errorf("%s: got %d %s", tc.query, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		if !bytes.HasPrefix(body, []byte(tc.prefix)) {
+			t.Errorf("%s: the image starts with %q", tc.query, body[:min(len(body), 8)])
+		}
+	}
+	for _, query := range []string{"?format=pdf", "?cell=0", "?labels=maybe"} {
+		if resp := do(t, "GET", ts.URL+"/jobs/"+job.ID+"/image"+query, "", nil); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", query, resp.StatusCode)
+		}
+	}
+	if resp := do(t, "GET", ts.URL+"/jobs/nope/image", "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown job: got %d, want 404", resp.StatusCode)
+	}
+}
+
+// A running job reports its progress, and cancelling it keeps the best
+// tiling found so far.
+func TestSolveImage(t *testing.T) {
+	ts := newTestServer(t, DefaultOptions())
+	tests := []struct {
+		query, contentType, prefix string
+	}{
+		{"?format=png", "image/png", "\x89PNG"},
+		{"?format=svg&cell=10", "image/svg+xml", "<?xml"},
+	}
+	for _, tc := range tests {
+		resp, err := http.Post(ts.URL+"/solve"+tc.query, "application/json", strings.NewReader(`{"rows": 5, "cols": 7}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != tc.contentType {
+			t.Errorf("%s: got %d %s", tc.query, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		if !bytes.HasPrefix(body, []byte(tc.prefix)) {
+			t.Errorf("%s: the image starts with %q", tc.query, body[:min(len(body), 8)])
+		}
+	}
+	for _, query := range []string{"?format=pdf", "?format=png&cell=0"} {
+		if resp := do(t, "POST", ts.URL+"/solve"+query, `{"rows": 5}`, nil); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestJobProgressAndCancel(t *testing.T) {
+	ts := newTestServer(t, DefaultOptions())
+	job := createJob(t, ts, fmt.Sprintf(`{"rows": %d, "timeout": "1m"}`, hardSide))
+	if job.Status != Queued && job.Status != Running {
+		t.Fatalf("new job is %s", job.Status)
+	}
+	running := waitJob(t, ts, job.ID, func(s JobStatus) bool {
+		return s.Status == Running && s.Placed > 0 && s.BestSquares > 0
+	})
+	if running.Result != nil {
+		t.Errorf("a running job has a result")
+	}
+	if resp := do(t, "GET", ts.URL+"/jobs/"+job.ID+"/image", "", nil); resp.StatusCode != http.StatusConflict {
+		t.Errorf("image of a running job: got %d, want 409", resp.StatusCode)
+	}
+
+	do(t, "DELETE", ts.URL+"/jobs/"+job.ID, "", nil)
+	status := waitJob(t, ts, job.ID, finished)
+	if status.Status != Done || status.Result.Optimal {
+		t.Fatalf("cancelled job: got %+v", status)
+	}
+	if err := tiling.Verify(hardSide, hardSide, status.Result.Squares); err != nil {
+		t.Error(err)
+	}
+}
+
+// With every slot taken POST /solve is refused, while jobs wait in the
+// queue until it is full.
+func TestConcurrencyCap(t *testing.T) {
+	opts := DefaultOptions()
+	opts.MaxConcurrent, opts.MaxQueued = 1, 2
+	ts := newTestServer(t, opts)
+
+	hard := fmt.Sprintf(`{"rows": %d, "timeout": "1m"}`, hardSide)
+	first := createJob(t, ts, hard)
+	waitJob(t, ts, first.ID, func(s JobStatus) bool { return s.Status == Running })
+
+	if resp := do(t, "POST", ts.URL+"/solve", `{"rows": 3}`, nil); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("POST /solve with no free slot: got %d, want 429", resp.StatusCode)
+	}
+	second := createJob(t, ts, `{"rows": 3}`)
+	if resp := do(t, "POST", ts.URL+"/jobs", `{"rows": 3}`, nil); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("POST /jobs with a full queue: got %d, want 503", resp.StatusCode)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if status := waitJob(t, ts, second.ID, func(JobStatus) bool { return true }); status.Status != Queued {
+		t.Errorf("second job is %s while the slot is taken", status.Status)
+	}
+
+	do(t, "DELETE", ts.URL+"/jobs/"+first.ID, "", nil)
+	if status := waitJob(t, ts, second.ID, finished); status.Status != Done || status.Result.MinSquares != 6 {
+		t.Errorf("second job: got %+v", status)
+	}
+	var result Result
+	if resp := do(t, "POST", ts.URL+"/solve", `{"rows": 3}`, &result); resp.StatusCode != http.StatusOK {
+		t.Errorf("POST /solve with a free slot: got %d", resp.StatusCode)
+	}
+}
+
+func TestFinishedJobsAreEvicted(t *testing.T) {
+	opts := DefaultOptions()
+	opts.JobTTL = 50 * time.Millisecond
+	ts := newTestServer(t, opts)
+
+	job := createJob(t, ts, `{"rows": 3}`)
+	waitJob(t, ts, job.ID, finished)
+	time.Sleep(100 * time.Millisecond)
+	if resp := do(t, "GET", ts.URL+"/jobs/"+job.ID, "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expired job: got %d, want 404", resp.StatusCode)
+	}
+
+	opts = DefaultOptions()
+	opts.MaxFinished = 2
+	ts = newTestServer(t, opts)
+	var ids []string
+	for i := 0; i < 3; i++ {
+		job := createJob(t, ts, `{"rows": 3}`)
+		waitJob(t, ts, job.ID, finished)
+		ids = append(ids, job.ID)
+	}
+	createJob(t, ts, `{"rows": 2}`)
+	if resp := do(t, "GET", ts.URL+"/jobs/"+ids[0], "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("oldest finished job: got %d, want 404", resp.StatusCode)
+	}
+	if resp := do(t, "GET", ts.URL+"/jobs/"+ids[2], "", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("newest finished job: got %d, want 200", resp.StatusCode)
+	}
+}
+
+// A panic of the solver fails the job instead of the server.
+func TestSolvePanicIsRecovered(t *testing.T) {
+	s := New(DefaultOptions())
+	solver, timeout, err := s.prepare(&Request{Rows: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	solver.Tracer = panicTracer{}
+	j := &job{id: "1", request: Request{Rows: 3, Cols: 3}, status: Queued, cancel: func() {}}
+	s.run(context.Background(), j, solver, timeout)
+	if status := j.snapshot(); status.Status != Failed || !strings.Contains(status.Error, "panic") {
+		t.Errorf("got %+v, want a failed job", status)
+	}
+}
+
+type panicTracer struct{}
+
+func (panicTracer) Trace(tiling.Event) { panic("tracer failed") }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"awesomeProject2/store"
+	"awesomeProject2/tiling"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// defaultStorePath returns the store file of the "store" subcommand in the
+// user cache directory, or "" when there is none. Solving only uses a store
+// when -store names one.
+func defaultStorePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "lb1", "tilings.json")
+}
+
+// storeTiling adds a proven-optimal tiling to st and saves it.
+func storeTiling(st *store.Store, N, M int, rule tiling.SizeRule, squares []tiling.Square) error {
+	if err := st.Put(N, M, rule, squares); err != nil {
+		return fmt.Errorf("storing tiling: %w", err)
+	}
+	return st.Save()
+}
+
+// runStore implements the "store" subcommand with its prefill, import and
+// export actions.
+func runStore(args []string) error {
+	fs := flag.NewFlagSet("store", flag.ExitOnError)
+	path := fs.String("store", defaultStorePath(), "Store file")
+	from := fs.Int("from", 2, "Smallest N solved by prefill")
+	to := fs.Int("to", 20, "Largest N solved by prefill")
+	workers := fs.Int("workers", 1, "Number of goroutines used by prefill")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: store [flags] prefill | import file | export [file]")
+		fmt.Fprintln(fs.Output(), "prefill solves every N×N board from -from to -to that is not stored yet.")
+		fmt.Fprintln(fs.Output(), "import checks and adds the tilings of another store file, export writes")
+		fmt.Fprintln(fs.Output(), "the store to file or to stdout.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *path == "" {
+		return fmt.Errorf("-store is required, the user cache directory is unknown")
+	}
+	st, err := store.Open(*path)
+	if err != nil {
+		return err
+	}
+	switch fs.Arg(0) {
+	case "prefill":
+		return prefillStore(st, *from, *to, *workers)
+	case "import":
+		if fs.NArg() < 2 {
+			return fmt.Errorf("import needs a file")
+		}
+		file, err := os.Open(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		n, err := st.Import(file)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d tilings, the store holds %d\n", n, st.Len())
+		return st.Save()
+	case "export":
+		var out io.Writer = os.Stdout
+		if fs.NArg() > 1 {
+			file, err := os.Create(fs.Arg(1))
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			out = file
+		}
+		return st.Export(out)
+	}
+	fs.Usage()
+	return fmt.Errorf("unknown store action %q", fs.Arg(0))
+}
+
+// prefillStore solves the N×N boards missing from st, saving after every
+// board so that an interrupted prefill keeps its progress.
+func prefillStore(st *store.Store, from, to, workers int) error {
+	for N := from; N <= to; N++ {
+		if _, ok := st.Get(N, N, tiling.SizeRule{}); ok {
+			fmt.Printf("N=%d: already stored\n", N)
+			continue
+		}
+		solver := tiling.NewSolver()
+		solver.Workers = workers
+		squares, err := solver.Solve(context.Background(), N)
+		if err != nil {
+			return fmt.Errorf("N=%d: %w", N, err)
+		}
+		if err := storeTiling(st, N, N, solver.Sizes, squares); err != nil {
+			return err
+		}
+		fmt.Printf("N=%d: %d squares, %d iterations\n", N, solver.MinSquares, solver.Iterations)
+	}
+	return nil
+}
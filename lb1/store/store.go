@@ -0,0 +1,250 @@
+// Package store keeps proven-optimal tilings on disk so that a board that
+// was solved once is answered without searching again.
+package store
+
+import (
+	"awesomeProject2/tiling"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Entry is an optimal tiling of a rows×cols board under a size rule.
+type Entry struct {
+	Rows    int             `json:"rows"`
+	Cols    int             `json:"cols"`
+	MinSize int             `json:"min_size,omitempty"`
+	MaxSize int             `json:"max_size,omitempty"`
+	Allowed []int           `json:"allowed,omitempty"`
+	Squares []tiling.Square `json:"squares"`
+}
+
+func (e Entry) rule() tiling.SizeRule {
+	return tiling.SizeRule{Min: e.MinSize, Max: e.MaxSize, Allowed: e.Allowed}
+}
+
+// Check verifies that the entry is an exact tiling of its board that only
+// uses squares its size rule allows.
+func (e Entry) Check() error {
+	if e.Rows <= 0 || e.Cols <= 0 {
+		return fmt.Errorf("invalid board size %dx%d", e.Rows, e.Cols)
+	}
+	rule := e.rule()
+	for _, square := range e.Squares {
+		if !rule.Allows(square.Size) {
+			return fmt.Errorf("square %v breaks the size rule", square)
+		}
+	}
+	return tiling.Verify(e.Rows, e.Cols, e.Squares)
+}
+
+// file is the on-disk form of a store.
+type file struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+const version = 1
+
+// Store maps board parameters to optimal tilings. Boards are kept with no
+// more rows than columns, a tiling of the transposed board is transposed
+// back on the way out.
+type Store struct {
+	path    string
+	entries map[string]Entry
+}
+
+// Open reads the store at path. A missing file gives an empty store that
+// is created by Save.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, entries: map[string]Entry{}}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := s.Import(f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// normalizeRule writes rules that allow the same squares on a rows×cols
+// board the same way: a minimum of 1 and a maximum no smaller than the
+// largest square of the board are dropped, and the whitelist is sorted
+// without repeats. A square board does not count as a square of its own,
+// so its largest square is one row smaller.
+func normalizeRule(rows, cols int, rule tiling.SizeRule) tiling.SizeRule {
+	if rule.Min <= 1 {
+		rule.Min = 0
+	}
+	largest := min(rows, cols)
+	if rows == cols {
+		largest--
+	}
+	if rule.Max >= largest {
+		rule.Max = 0
+	}
+	if len(rule.Allowed) > 0 {
+		allowed := append([]int{}, rule.Allowed...)
+		sort.Ints(allowed)
+		rule.Allowed = slices.Compact(allowed)
+	} else {
+		rule.Allowed = nil
+	}
+	return rule
+}
+
+// key identifies a board. The rule is normalized, so rules allowing the
+// same squares share a key.
+func key(rows, cols int, rule tiling.SizeRule) string {
+	rule = normalizeRule(rows, cols, rule)
+	parts := make([]string, len(rule.Allowed))
+	for i, size := range rule.Allowed {
+		parts[i] = strconv.Itoa(size)
+	}
+	return fmt.Sprintf("%dx%d min=%d max=%d allowed=%s", rows, cols, rule.Min, rule.Max, strings.Join(parts, ","))
+}
+
+func transpose(squares []tiling.Square) []tiling.Square {
+	result := make([]tiling.Square, len(squares))
+	for i, square := range squares {
+		result[i] = tiling.Square{X: square.Y, Y: square.X, Size: square.Size}
+	}
+	return result
+}
+
+// normalize turns an entry of a board with more rows than columns into
+// one of the transposed board and normalizes its size rule.
+func normalize(e Entry) Entry {
+	if e.Rows > e.Cols {
+		e.Rows, e.Cols = e.Cols, e.Rows
+		e.Squares = transpose(e.Squares)
+	}
+	rule := normalizeRule(e.Rows, e.Cols, e.rule())
+	e.MinSize, e.MaxSize, e.Allowed = rule.Min, rule.Max, rule.Allowed
+	return e
+}
+
+// Get returns the stored optimal tiling of a rows×cols board under rule.
+func (s *Store) Get(rows, cols int, rule tiling.SizeRule) ([]tiling.Square, bool) {
+	transposed := rows > cols
+	if transposed {
+		rows, cols = cols, rows
+	}
+	e, ok := s.entries[key(rows, cols, rule)]
+	if !ok {
+		return nil, false
+	}
+	squares := append([]tiling.Square{}, e.Squares...)
+	if transposed {
+		squares = transpose(squares)
+	}
+	return squares, true
+}
+
+// Put records an optimal tiling after checking it. A tiling with more
+// squares than the stored one is ignored.
+func (s *Store) Put(rows, cols int, rule tiling.SizeRule, squares []tiling.Square) error {
+	e := normalize(Entry{
+		Rows: rows, Cols: cols,
+		MinSize: rule.Min, MaxSize: rule.Max, Allowed: rule.Allowed,
+		Squares: append([]tiling.Square{}, squares...),
+	})
+	return s.add(e)
+}
+
+func (s *Store) add(e Entry) error {
+	if err := e.Check(); err != nil {
+		return fmt.Errorf("%dx%d: %w", e.Rows, e.Cols, err)
+	}
+	k := key(e.Rows, e.Cols, e.rule())
+	if old, ok := s.entries[k]; ok && len(old.Squares) <= len(e.Squares) {
+		return nil
+	}
+	s.entries[k] = e
+	return nil
+}
+
+// Len returns the number of stored tilings.
+func (s *Store) Len() int {
+	return len(s.entries)
+}
+
+// Import adds the entries of a store file read from r and returns how many
+// there were. Every entry is checked first, and nothing is added if one of
+// them fails.
+func (s *Store) Import(r io.Reader) (int, error) {
+	var f file
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return 0, err
+	}
+	if f.Version != version {
+		return 0, fmt.Errorf("unsupported store version %d", f.Version)
+	}
+	for i, e := range f.Entries {
+		if err := e.Check(); err != nil {
+			return 0, fmt.Errorf("entry %d (%dx%d): %w", i+1, e.Rows, e.Cols, err)
+		}
+	}
+	for _, e := range f.Entries {
+		s.add(normalize(e))
+	}
+	return len(f.Entries), nil
+}
+
+// Export writes every entry to w, ordered by board size.
+func (s *Store) Export(w io.Writer) error {
+	f := file{Version: version, Entries: make([]Entry, 0, len(s.entries))}
+	keys := make([]string, 0, len(s.entries))
+	for k := range s.entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := s.entries[keys[i]], s.entries[keys[j]]
+		if a.Rows != b.Rows {
+			return a.Rows < b.Rows
+		}
+		if a.Cols != b.Cols {
+			return a.Cols < b.Cols
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		f.Entries = append(f.Entries, s.entries[k])
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(f)
+}
+
+// Save writes the store back to its file. The data goes to a temporary
+// file first, so an interrupted save leaves the old store intact.
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	if err := s.Export(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"awesomeProject2/tiling"
+	"bytes"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// tiling2x3 is an optimal tiling of the 2×3 board.
+var tiling2x3 = []tiling.Square{{X: 0, Y: 0, Size: 2}, {X: 0, Y: 2, Size: 1}, {X: 1, Y: 2, Size: 1}}
+
+// Rules that allow the same squares must share one entry.
+func TestEquivalentRulesShareAnEntry(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "tilings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := []tiling.SizeRule{
+		{},
+		{Min: 1},
+		{Max: 2},
+		{Min: 1, Max: 5},
+	}
+	for _, rule := range rules {
+		if err := s.Put(2, 3, rule, tiling2x3); err != nil {
+			t.Fatalf("Put(%+v): %v", rule, err)
+		}
+	}
+	if s.Len() != 1 {
+		t.Errorf("got %d entries, want 1", s.Len())
+	}
+	for _, rule := range rules {
+		if _, ok := s.Get(2, 3, rule); !ok {
+			t.Errorf("Get(%+v) missed", rule)
+		}
+	}
+	if _, ok := s.Get(2, 3, tiling.SizeRule{Min: 2}); ok {
+		t.Errorf("Get with min 2 hit the entry of all sizes")
+	}
+
+	if err := s.Put(2, 2, tiling.SizeRule{Allowed: []int{1, 1}}, []tiling.Square{
+		{X: 0, Y: 0, Size: 1}, {X: 0, Y: 1, Size: 1}, {X: 1, Y: 0, Size: 1}, {X: 1, Y: 1, Size: 1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get(2, 2, tiling.SizeRule{Allowed: []int{1}}); !ok {
+		t.Errorf("Get missed a whitelist with a repeated size")
+	}
+
+	// A square board holds no square of its own side, so on the 3×3
+	// board a maximum of 2 allows every size that fits.
+	if err := s.Put(3, 3, tiling.SizeRule{}, []tiling.Square{
+		{X: 0, Y: 0, Size: 2}, {X: 0, Y: 2, Size: 1}, {X: 1, Y: 2, Size: 1},
+		{X: 2, Y: 0, Size: 1}, {X: 2, Y: 1, Size: 1}, {X: 2, Y: 2, Size: 1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range []tiling.SizeRule{{Max: 2}, {Min: 1, Max: 3}} {
+		if _, ok := s.Get(3, 3, rule); !ok {
+			t.Errorf("Get(3, 3, %+v) missed", rule)
+		}
+	}
+	if _, ok := s.Get(3, 3, tiling.SizeRule{Max: 1}); ok {
+		t.Errorf("Get with max 1 hit the entry of all sizes")
+	}
+	if s.Len() != 3 {
+		t.Errorf("got %d entries, want 3", s.Len())
+	}
+}
+
+func TestSaveAndReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tilings.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(3, 2, tiling.SizeRule{Min: 1}, transpose(tiling2x3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := reopened.Get(3, 2, tiling.SizeRule{})
+	if !ok || !slices.Equal(got, transpose(tiling2x3)) {
+		t.Errorf("got %v, %v, want the transposed tiling", got, ok)
+	}
+	var buf bytes.Buffer
+	if err := reopened.Export(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("min_size")) {
+		t.Errorf("the saved entry kept the minimum of 1:\n%s", buf.String())
+	}
+}
+
+func TestImportRejectsInvalidEntries(t *testing.T) {
+	s, _ := Open(filepath.Join(t.TempDir(), "tilings.json"))
+	bad := `{"version": 1, "entries": [{"rows": 2, "cols": 2, "squares": [{"x": 1, "y": 1, "size": 1}]}]}`
+	if _, err := s.Import(bytes.NewBufferString(bad)); err == nil {
+		t.Errorf("Import accepted a tiling with gaps")
+	}
+	if s.Len() != 0 {
+		t.Errorf("got %d entries, want 0", s.Len())
+	}
+}
@@ -0,0 +1,103 @@
+package tiling
+
+import (
+	"awesomeProject2/internal/blocks"
+	"math/bits"
+)
+
+// freeRun returns the number of free cells in row x starting at column y.
+func (g *grid) freeRun(x, y int) int {
+	row := g.row(x)
+	run := 0
+	for w := y / wordBits; w < g.words; w++ {
+		lo := Max(y-w*wordBits, 0)
+		used := row[w] & segmentMask(w, y, g.cols-y)
+		if used != 0 {
+			return run + bits.TrailingZeros64(used) - lo
+		}
+		run += Min(g.cols-w*wordBits, wordBits) - lo
+	}
+	return run
+}
+
+// freeCellsFrom counts the free cells in rows x and below.
+func (g *grid) freeCellsFrom(x int) int {
+	used := 0
+	for _, word := range g.cells[x*g.words:] {
+		used += bits.OnesCount64(word)
+	}
+	return (g.rows-x)*g.cols - used
+}
+
+// largestFit returns the largest square not above maxSz that fits at the
+// free cell (x, y). Every smaller square fits as well.
+func (g *grid) largestFit(x, y, maxSz int) int {
+	size := Min(maxSz, g.freeRun(x, y))
+	for size > 1 && !g.canPlace(x, y, size) {
+		size--
+	}
+	return size
+}
+
+// lowerBound returns the fewest squares that can still cover the free
+// cells of the board when (x, y) is the first free one. Every square placed
+// from now on has its top row at x or below, so none is larger than side.
+// The bound is the larger of two estimates: the free area divided by the
+// largest square area, and the free runs of row x, which can only be
+// covered by squares whose top row is x. Limits on the number of sizes
+// add a third estimate, see board.distinctBound.
+func (b *branch) lowerBound(occupied *grid, x, y int, current []Square) int {
+	side := Min(Min(b.board.rows-x, b.board.cols), b.board.maxSide)
+	if b.board.sizes.Max > 0 {
+		side = Min(side, b.board.sizes.Max)
+	}
+	if side < 1 {
+		// No square fits at all, as on a 1×1 board without the initial
+		// squares, so the free cells can never be covered.
+		return initialBound
+	}
+	free := occupied.freeCellsFrom(x)
+	area := blocks.CeilDiv(free, side*side)
+	if b.board.distinct() {
+		area = Max(area, b.board.distinctBound(free, side, current))
+	}
+
+	rowBound := 0
+	for c := y; c < b.board.cols; c++ {
+		if run := occupied.freeRun(x, c); run > 0 {
+			rowBound += blocks.CeilDiv(run, side)
+			c += run
+		}
+	}
+	return Max(area, rowBound)
+}
+
+// candidates lists the sizes to try at the free cell (x, y), largest
+// first. With pruning the square whose bottom edge lines up with the end
+// of the occupied cells to its left comes second, as such squares tend to
+// leave fewer ragged gaps.
+func (b *board) candidates(occupied *grid, x, y, maxSz int, buf []int) []int {
+	if maxSz < 1 {
+		return buf
+	}
+	aligned := 0
+	if b.pruning && y > 0 {
+		h := 0
+		for x+h < b.rows && occupied.isOccupied(x+h, y-1) {
+			h++
+		}
+		if h < maxSz {
+			aligned = h
+		}
+	}
+	buf = append(buf, maxSz)
+	if aligned > 0 {
+		buf = append(buf, aligned)
+	}
+	for size := maxSz - 1; size >= 1; size-- {
+		if size != aligned {
+			buf = append(buf, size)
+		}
+	}
+	return buf
+}
@@ -0,0 +1,109 @@
+package tiling
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"time"
+)
+
+// Checkpoint is the state of an interrupted sequential backtracking search
+// of a rows×cols board. Squares are in the coordinates of the scaled board
+// the search runs on.
+type Checkpoint struct {
+	Rows    int      `json:"rows"`
+	Cols    int      `json:"cols"`
+	Sizes   SizeRule `json:"sizes"`
+	Pruning bool     `json:"pruning"`
+	// Perfect and Distinct are the limits on the number of sizes,
+	// Coprime and FaultFree the quilt and fault-free rules.
+	Perfect   bool `json:"perfect,omitempty"`
+	Distinct  int  `json:"distinct,omitempty"`
+	Coprime   bool `json:"coprime,omitempty"`
+	FaultFree bool `json:"fault_free,omitempty"`
+	// Path is the stack of placed squares, initial squares included, at
+	// the node the search was about to visit.
+	Path []Square `json:"path"`
+	// Best is the best tiling found so far, nil if there is none.
+	Best       []Square `json:"best"`
+	Iterations int      `json:"iterations"`
+}
+
+// ErrCheckpointMismatch is returned when a checkpoint was written for
+// another board or solver configuration.
+var ErrCheckpointMismatch = errors.New("checkpoint does not match the board or the solver settings")
+
+func ReadCheckpoint(r io.Reader) (*Checkpoint, error) {
+	cp := &Checkpoint{}
+	if err := json.NewDecoder(r).Decode(cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+func WriteCheckpoint(w io.Writer, cp *Checkpoint) error {
+	return json.NewEncoder(w).Encode(cp)
+}
+
+// matches reports whether cp can resume a search of a rows×cols board
+// started from initialSquares with the settings of s.
+func (cp *Checkpoint) matches(s *Solver, rows, cols int, initialSquares []Square) bool {
+	return cp.Rows == rows && cp.Cols == cols && cp.Pruning == s.Pruning &&
+		cp.Perfect == s.Perfect && cp.Distinct == s.DistinctSizes &&
+		cp.Coprime == s.Coprime && cp.FaultFree == s.FaultFree &&
+		cp.Sizes.Min == s.Sizes.Min && cp.Sizes.Max == s.Sizes.Max &&
+		slices.Equal(cp.Sizes.Allowed, s.Sizes.Allowed) &&
+		len(cp.Path) >= len(initialSquares) && slices.Equal(cp.Path[:len(initialSquares)], initialSquares)
+}
+
+// checkpointer writes the checkpoints of one branch.
+type checkpointer struct {
+	rows, cols int
+	sizes      SizeRule
+	perfect    bool
+	distinct   int
+	coprime    bool
+	faultFree  bool
+	pruning    bool
+	every      time.Duration
+	last       time.Time
+	save       func(*Checkpoint)
+}
+
+// checkpoint hands the state of the branch at the node of current to the
+// save callback.
+func (b *branch) checkpoint(current []Square) {
+	c := b.checkpointer
+	c.last = time.Now()
+	var best []Square
+	if b.best != nil {
+		best = append([]Square{}, b.best...)
+	}
+	c.save(&Checkpoint{
+		Rows:       c.rows,
+		Cols:       c.cols,
+		Sizes:      c.sizes,
+		Pruning:    c.pruning,
+		Perfect:    c.perfect,
+		Distinct:   c.distinct,
+		Coprime:    c.coprime,
+		FaultFree:  c.faultFree,
+		Path:       append([]Square{}, current...),
+		Best:       best,
+		Iterations: b.iterations,
+	})
+}
+
+// resumeFrom prepares a branch to walk down the path of cp, which starts
+// with initial squares, before it goes on with the regular search. The
+// best tiling of cp is taken over unless the bound is already lower, as
+// after a heuristic run. The nodes of the walk down, the checkpoint node
+// included, are already in cp.Iterations and are counted again.
+func (b *branch) resumeFrom(cp *Checkpoint, initial int) {
+	if cp.Best != nil && b.tryUpdate(len(cp.Best)) {
+		b.best = append([]Square{}, cp.Best...)
+	}
+	b.resume = cp.Path
+	b.iterations = cp.Iterations - (len(cp.Path) - initial + 1)
+}
@@ -0,0 +1,166 @@
+package tiling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Factor is the optimal tiling of the p×p board for a prime factor p.
+type Factor struct {
+	Prime      int
+	MinSquares int
+	Squares    []Square
+	// Looked is true when the tiling came from the lookup instead of a
+	// search.
+	Looked bool
+}
+
+// Decomposition tiles a composite N×N board by scaling up the optimal
+// tiling of one of its prime factors. Every k×k tiling scaled by N/k is a
+// tiling of the N×N board, so the best factor gives an upper bound on the
+// optimum. The bound is only known to be the optimum when Checked is set.
+type Decomposition struct {
+	N       int
+	Factors []Factor
+	// Best is the index in Factors of the factor used.
+	Best    int
+	Squares []Square
+	// Checked is set when an exact search of the unscaled board confirmed
+	// the count. Improved is set when that search found fewer squares, in
+	// which case Squares is its tiling.
+	Checked  bool
+	Improved bool
+}
+
+// DecomposeOptions configure Solver.Decompose.
+type DecomposeOptions struct {
+	// Lookup, when set, is asked for the optimal tiling of a prime board
+	// before it is searched.
+	Lookup func(p int) ([]Square, bool)
+	// CheckUpTo is the largest N whose decomposition is checked with an
+	// exact search of the whole board.
+	CheckUpTo int
+}
+
+// PrimeFactors returns the distinct prime factors of n in increasing order.
+func PrimeFactors(n int) []int {
+	var primes []int
+	for p := 2; p*p <= n; p++ {
+		if n%p == 0 {
+			primes = append(primes, p)
+			for n%p == 0 {
+				n /= p
+			}
+		}
+	}
+	if n > 1 {
+		primes = append(primes, n)
+	}
+	return primes
+}
+
+// Decompose solves the board of every prime factor of N and scales up the
+// tiling with the fewest squares. For N up to opts.CheckUpTo the result is
+// compared with an exact search of the unscaled N×N board. The solver
+// fields describe the final result: Optimal is set for a prime N or a
+// checked decomposition.
+func (s *Solver) Decompose(ctx context.Context, N int, opts DecomposeOptions) (*Decomposition, error) {
+	if !s.shortcuts() {
+		return nil, errors.New("decomposition needs every square size and no tiling rules, scaling changes the sizes")
+	}
+	if N < 2 {
+		return nil, fmt.Errorf("invalid board size %d", N)
+	}
+
+	d := &Decomposition{N: N}
+	iterations := 0
+	for _, p := range PrimeFactors(N) {
+		f := Factor{Prime: p}
+		if opts.Lookup != nil {
+			f.Squares, f.Looked = opts.Lookup(p)
+		}
+		if !f.Looked {
+			squares, err := s.Solve(ctx, p)
+			iterations += s.Iterations
+			if err != nil {
+				return nil, fmt.Errorf("factor %d: %w", p, err)
+			}
+			if !s.Optimal {
+				return nil, fmt.Errorf("factor %d: %w", p, ctx.Err())
+			}
+			f.Squares = squares
+		}
+		f.MinSquares = len(f.Squares)
+		d.Factors = append(d.Factors, f)
+		if f.MinSquares < d.Factors[d.Best].MinSquares {
+			d.Best = len(d.Factors) - 1
+		}
+	}
+	best := d.Factors[d.Best]
+	d.Squares = upscaleSquares(best.Squares, N/best.Prime)
+
+	optimal := len(d.Factors) == 1 && d.Factors[0].Prime == N
+	if !optimal && N <= opts.CheckUpTo {
+		exact, err := s.solvePlain(ctx, N)
+		iterations += s.Iterations
+		if err != nil {
+			return nil, fmt.Errorf("checking %dx%d: %w", N, N, err)
+		}
+		if s.Optimal {
+			d.Checked, optimal = true, true
+			if len(exact) < len(d.Squares) {
+				d.Improved, d.Squares = true, exact
+			}
+		}
+	}
+
+	s.MinSquares, s.BestResult = len(d.Squares), d.Squares
+	s.Iterations, s.Optimal = iterations, optimal
+	return d, nil
+}
+
+// solvePlain searches the N×N board without scaling it down and without
+// the initial corner squares.
+func (s *Solver) solvePlain(ctx context.Context, N int) ([]Square, error) {
+	s.Reset()
+	bd := newBoard(N, N)
+	bd.sizes = s.Sizes
+	bd.pruning = s.Pruning
+	bd.cornerLimit = true
+	err := s.run(ctx, bd, bd.newGrid(), []Square{}, nil)
+	return s.BestResult, err
+}
+
+// Explain describes in words which factor was used and how far the result
+// is known to be optimal.
+func (d *Decomposition) Explain() string {
+	var b strings.Builder
+	if len(d.Factors) == 1 && d.Factors[0].Prime == d.N {
+		fmt.Fprintf(&b, "%d is prime, so the board cannot be decomposed; it was solved directly with %d squares.", d.N, len(d.Squares))
+		return b.String()
+	}
+
+	parts := make([]string, len(d.Factors))
+	for i, f := range d.Factors {
+		source := "searched"
+		if f.Looked {
+			source = "looked up"
+		}
+		parts[i] = fmt.Sprintf("%d → %d squares (%s)", f.Prime, f.MinSquares, source)
+	}
+	best := d.Factors[d.Best]
+	fmt.Fprintf(&b, "Prime factors of %d: %s.\n", d.N, strings.Join(parts, ", "))
+	fmt.Fprintf(&b, "Used factor %d: its %d-square tiling scaled by %d tiles the %dx%d board.\n",
+		best.Prime, best.MinSquares, d.N/best.Prime, d.N, d.N)
+	switch {
+	case d.Improved:
+		fmt.Fprintf(&b, "An exact search of the %dx%d board found a better tiling with %d squares, which is used instead.", d.N, d.N, len(d.Squares))
+	case d.Checked:
+		fmt.Fprintf(&b, "An exact search of the %dx%d board confirms that %d squares are optimal.", d.N, d.N, len(d.Squares))
+	default:
+		fmt.Fprintf(&b, "Not checked by an exact search of the whole board, so %d squares is an upper bound.", len(d.Squares))
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package tiling
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestPrimeFactors(t *testing.T) {
+	tests := []struct {
+		n    int
+		want []int
+	}{
+		{2, []int{2}}, {12, []int{2, 3}}, {49, []int{7}}, {60, []int{2, 3, 5}}, {97, []int{97}},
+	}
+	for _, tc := range tests {
+		if got := PrimeFactors(tc.n); !slices.Equal(got, tc.want) {
+			t.Errorf("PrimeFactors(%d) = %v, want %v", tc.n, got, tc.want)
+		}
+	}
+}
+
+// For every composite N up to 20 the scaled tiling of a prime factor has
+// as many squares as an exact search of the whole board.
+func TestDecomposeMatchesExactSearch(t *testing.T) {
+	for n := 4; n <= 20; n++ {
+		if len(PrimeFactors(n)) == 1 && PrimeFactors(n)[0] == n {
+			continue
+		}
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			exact := NewSolver()
+			want, err := exact.solvePlain(context.Background(), n)
+			if err != nil || !exact.Optimal {
+				t.Fatalf("exact search: %v, optimal %v", err, exact.Optimal)
+			}
+
+			s := NewSolver()
+			d, err := s.Decompose(context.Background(), n, DecomposeOptions{CheckUpTo: 20})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Verify(n, n, d.Squares); err != nil {
+				t.Fatal(err)
+			}
+			if len(d.Squares) != len(want) || !d.Checked || d.Improved || !s.Optimal {
+				t.Errorf("got %d squares, checked %v, improved %v, optimal %v, want %d checked squares",
+					len(d.Squares), d.Checked, d.Improved, s.Optimal, len(want))
+			}
+		})
+	}
+}
+
+func TestDecomposeOptions(t *testing.T) {
+	// Without a check the decomposition is only an upper bound.
+	s := NewSolver()
+	d, err := s.Decompose(context.Background(), 35, DecomposeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(35, 35, d.Squares); err != nil {
+		t.Fatal(err)
+	}
+	if d.Checked || s.Optimal || d.Factors[d.Best].Prime != 5 || len(d.Squares) != 8 {
+		t.Errorf("got %d squares from factor %d, checked %v, optimal %v, want 8 unchecked squares from 5",
+			len(d.Squares), d.Factors[d.Best].Prime, d.Checked, s.Optimal)
+	}
+
+	// Factors found by the lookup are not searched.
+	lookup := func(p int) ([]Square, bool) {
+		if p != 7 {
+			return nil, false
+		}
+		return solveChecked(t, NewSolver(), 7), true
+	}
+	d, err = NewSolver().Decompose(context.Background(), 14, DecomposeOptions{Lookup: lookup})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Factors[0].Looked || !d.Factors[1].Looked || d.Factors[1].MinSquares != 9 {
+		t.Errorf("got factors %+v, want 7 looked up with 9 squares", d.Factors)
+	}
+
+	// A prime board is solved directly.
+	s = NewSolver()
+	if d, err = s.Decompose(context.Background(), 11, DecomposeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Squares) != 11 || !s.Optimal {
+		t.Errorf("got %d squares, optimal %v, want 11 optimal squares", len(d.Squares), s.Optimal)
+	}
+
+	s = NewSolver()
+	s.Sizes = NoUnitSquares()
+	if _, err := s.Decompose(context.Background(), 6, DecomposeOptions{}); err == nil {
+		t.Error("Decompose accepted a size rule")
+	}
+	if _, err := NewSolver().Decompose(context.Background(), 1, DecomposeOptions{}); err == nil {
+		t.Error("Decompose accepted N=1")
+	}
+}
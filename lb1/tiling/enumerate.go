@@ -0,0 +1,163 @@
+package tiling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrNotProven is returned by Enumerate when the search for the minimum
+// square count stops before it proves its result, so that there is no
+// count to list the tilings of.
+var ErrNotProven = errors.New("the minimum square count was not proven")
+
+// Enumeration lists every minimum tiling of an N×N board.
+type Enumeration struct {
+	N          int
+	MinSquares int
+	// Tilings holds every minimum tiling, Distinct one representative in
+	// canonical form for each class under the 8 symmetries of the square.
+	Tilings  [][]Square
+	Distinct [][]Square
+	// Complete is false when the context stopped the enumeration early.
+	Complete bool
+}
+
+// Enumerate finds the optimal square count with Solve and then lists all
+// tilings that reach it. The scaling and initial-square shortcuts of Solve
+// only keep one tiling, so the listing searches the plain board. If the
+// count is not proven, because ctx stopped the search or the engine proves
+// nothing, the error wraps ErrNotProven and ctx.Err() if set.
+func (s *Solver) Enumerate(ctx context.Context, N int) (*Enumeration, error) {
+	e := &Enumeration{N: N}
+	if _, err := s.Solve(ctx, N); err != nil {
+		return e, err
+	}
+	if !s.Optimal {
+		if err := ctx.Err(); err != nil {
+			return e, fmt.Errorf("%w: %w", ErrNotProven, err)
+		}
+		return e, fmt.Errorf("%w by the %s engine", ErrNotProven, s.Engine)
+	}
+	e.MinSquares = s.MinSquares
+
+	bd := newBoard(N, N)
+	bd.sizes = s.Sizes
+	bd.perfect, bd.maxDistinct = s.Perfect, s.DistinctSizes
+	bd.coprime, bd.faultFree = s.Coprime, s.FaultFree
+	b := newBranch(bd, newShared(ctx, 1, initialBound), 0)
+	seen := map[string]bool{}
+	b.enumerate(initializeGrid(N, N), []Square{}, 0, s.MinSquares, func(tiling []Square) {
+		tiling = append([]Square{}, tiling...)
+		e.Tilings = append(e.Tilings, tiling)
+		canonical := Canonical(N, tiling)
+		if key := tilingKey(canonical); !seen[key] {
+			seen[key] = true
+			e.Distinct = append(e.Distinct, canonical)
+		}
+	})
+	s.Iterations += b.iterations
+	e.Complete = !b.shared.stopped.Load()
+	return e, nil
+}
+
+// enumerate reports every completion of current that uses exactly limit
+// squares.
+func (b *branch) enumerate(occupied *grid, current []Square, from, limit int, report func([]Square)) {
+	b.iterations++
+	if b.stopped() {
+		return
+	}
+	pos := occupied.findFirstFreePosition(from)
+	if b.board.rejects(occupied, current, from, pos) {
+		return
+	}
+	if pos == -1 {
+		if len(current) == limit {
+			report(current)
+		}
+		return
+	}
+	if len(current) == limit {
+		return
+	}
+
+	x, y := pos/b.board.cols, pos%b.board.cols
+	for size := b.board.maxSize(x, y); size >= 1; size-- {
+		if b.board.allowed(x, y, size, current) && occupied.canPlace(x, y, size) {
+			square := occupied.placeSquare(x, y, size)
+			b.enumerate(occupied, append(current, square), pos, limit, report)
+			occupied.removeSquare(square)
+		}
+	}
+}
+
+// transform applies the k-th symmetry of the N×N square (k in 0..7) to a
+// cell.
+func transform(k, N, x, y int) (int, int) {
+	if k&4 != 0 {
+		x, y = y, x
+	}
+	if k&2 != 0 {
+		x = N - 1 - x
+	}
+	if k&1 != 0 {
+		y = N - 1 - y
+	}
+	return x, y
+}
+
+// Canonical returns the lexicographically smallest sorted form of a tiling
+// among its images under the 8 symmetries of the N×N square. Two tilings
+// are equal up to symmetry exactly when their canonical forms are equal.
+func Canonical(N int, tiling []Square) []Square {
+	var best []Square
+	for k := 0; k < 8; k++ {
+		image := make([]Square, len(tiling))
+		for i, square := range tiling {
+			x1, y1 := transform(k, N, square.X, square.Y)
+			x2, y2 := transform(k, N, square.X+square.Size-1, square.Y+square.Size-1)
+			image[i] = Square{Min(x1, x2), Min(y1, y2), square.Size}
+		}
+		sortSquares(image)
+		if best == nil || lessTiling(image, best) {
+			best = image
+		}
+	}
+	return best
+}
+
+func sortSquares(squares []Square) {
+	sort.Slice(squares, func(i, j int) bool {
+		return lessSquare(squares[i], squares[j])
+	})
+}
+
+func lessSquare(a, b Square) bool {
+	if a.X != b.X {
+		return a.X < b.X
+	}
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+	return a.Size < b.Size
+}
+
+func lessTiling(a, b []Square) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return lessSquare(a[i], b[i])
+		}
+	}
+	return false
+}
+
+func tilingKey(tiling []Square) string {
+	key := make([]byte, 0, len(tiling)*8)
+	for _, square := range tiling {
+		key = append(key, square.String()...)
+		key = append(key, ';')
+	}
+	return string(key)
+}
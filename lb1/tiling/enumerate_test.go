@@ -0,0 +1,50 @@
+package tiling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestEnumerateCounts(t *testing.T) {
+	tests := []struct{ n, squares, tilings, distinct int }{
+		{5, 8, 32, 5},
+		{7, 9, 28, 4},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprint(tc.n), func(t *testing.T) {
+			e, err := NewSolver().Enumerate(context.Background(), tc.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !e.Complete || e.MinSquares != tc.squares {
+				t.Fatalf("got %d squares, complete %v, want %d", e.MinSquares, e.Complete, tc.squares)
+			}
+			if len(e.Tilings) != tc.tilings || len(e.Distinct) != tc.distinct {
+				t.Errorf("got %d tilings, %d up to symmetry, want %d and %d",
+					len(e.Tilings), len(e.Distinct), tc.tilings, tc.distinct)
+			}
+			for _, squares := range append(e.Tilings, e.Distinct...) {
+				if err := Verify(tc.n, tc.n, squares); err != nil {
+					t.Fatalf("%v: %v", squares, err)
+				}
+			}
+		})
+	}
+}
+
+func TestEnumerateNotProven(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewSolver().Enumerate(ctx, 41)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled: got %v, want context.Canceled", err)
+	}
+
+	s := NewSolver()
+	s.Engine = Heuristic
+	if _, err := s.Enumerate(context.Background(), 7); !errors.Is(err, ErrNotProven) {
+		t.Errorf("heuristic engine: got %v, want ErrNotProven", err)
+	}
+}
@@ -0,0 +1,111 @@
+package tiling
+
+import (
+	"awesomeProject2/dlx"
+	"context"
+	"errors"
+)
+
+// maxExactCoverNodes caps the 1s of the exact cover matrix. Each takes
+// about 50 bytes, and a large board without the corner squares has tens
+// of millions of them.
+const maxExactCoverNodes = 4 << 20
+
+// ErrExactCoverTooLarge is returned when the exact cover problem of a
+// board has more than maxExactCoverNodes 1s.
+var ErrExactCoverTooLarge = errors.New("board is too large for the dlx engine")
+
+// exactCover is the free cells of a board as an exact cover problem: one
+// column per free cell and one row per square that may be placed on free
+// cells only.
+type exactCover struct {
+	matrix  *dlx.Matrix
+	squares []Square
+}
+
+// newExactCover builds the problem of the free cells of occupied. Rows are
+// added largest square first, so that Algorithm X tries large squares
+// before small ones in every column. It gives up with the context error
+// when ctx is done and with ErrExactCoverTooLarge above the node cap.
+func newExactCover(ctx context.Context, bd *board, occupied *grid, initialSquares []Square) (*exactCover, error) {
+	columns := make([]int, bd.rows*bd.cols)
+	free := 0
+	for pos := range columns {
+		if occupied.isOccupied(pos/bd.cols, pos%bd.cols) {
+			columns[pos] = -1
+		} else {
+			columns[pos] = free
+			free++
+		}
+	}
+
+	ec := &exactCover{matrix: dlx.New(free, 0)}
+	cells := []int{}
+	nodes := 0
+	for size := Min(Min(bd.rows, bd.cols), bd.maxSide); size >= 1; size-- {
+		for x := 0; x+size <= bd.rows; x++ {
+			// The largest squares come first and each adds size*size
+			// nodes, so the context is checked once per row of positions
+			// rather than after a count of them.
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			for y := 0; y+size <= bd.cols; y++ {
+				if size > bd.maxSize(x, y) || !bd.allowed(x, y, size, initialSquares) || !occupied.canPlace(x, y, size) {
+					continue
+				}
+				if nodes += size * size; nodes > maxExactCoverNodes {
+					return nil, ErrExactCoverTooLarge
+				}
+				cells = cells[:0]
+				for i := x; i < x+size; i++ {
+					for j := y; j < y+size; j++ {
+						cells = append(cells, columns[i*bd.cols+j])
+					}
+				}
+				if _, err := ec.matrix.AddRow(cells); err != nil {
+					return nil, err
+				}
+				ec.squares = append(ec.squares, Square{x, y, size})
+			}
+		}
+	}
+	return ec, nil
+}
+
+// searchDLX runs the exact cover engine from the initial squares already
+// placed on occupied, looking for tilings with fewer than bound squares.
+// Workers and the tracer are not used by it.
+func (s *Solver) searchDLX(ctx context.Context, bd *board, occupied *grid, initialSquares []Square, bound int) (*shared, error) {
+	sh := newShared(ctx, 1, bound)
+	// MinCover takes a budget of 0 as no bound at all, while here nothing
+	// is left to look for.
+	budget := bound - len(initialSquares)
+	if budget <= 0 {
+		return sh, nil
+	}
+	ec, err := newExactCover(ctx, bd, occupied, initialSquares)
+	if err != nil {
+		if ctx.Err() == nil {
+			return nil, err
+		}
+		// The context ended while the matrix was built, which stops the
+		// search before it began.
+		sh.stopped.Store(true)
+		return sh, nil
+	}
+	rows, err := ec.matrix.MinCover(ctx, budget)
+	s.Iterations = ec.matrix.Nodes
+	if err != nil {
+		sh.stopped.Store(true)
+	}
+	if rows == nil {
+		return sh, nil
+	}
+	s.BestResult = append([]Square{}, initialSquares...)
+	for _, r := range rows {
+		s.BestResult = append(s.BestResult, ec.squares[r])
+	}
+	s.MinSquares = len(s.BestResult)
+	return sh, nil
+}
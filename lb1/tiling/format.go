@@ -0,0 +1,70 @@
+package tiling
+
+import (
+	"awesomeProject2/internal/blocks"
+	"encoding/json"
+	"io"
+)
+
+// Tiling is a board together with the squares covering it.
+type Tiling struct {
+	Rows    int      `json:"rows"`
+	Cols    int      `json:"cols"`
+	Squares []Square `json:"squares"`
+}
+
+// jsonSquare is the JSON form of a Square. Coordinates are 1-based, as in
+// Square.String.
+type jsonSquare struct {
+	X    int `json:"x"`
+	Y    int `json:"y"`
+	Size int `json:"size"`
+}
+
+func (s Square) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonSquare{s.X + 1, s.Y + 1, s.Size})
+}
+
+func (s *Square) UnmarshalJSON(data []byte) error {
+	var js jsonSquare
+	if err := json.Unmarshal(data, &js); err != nil {
+		return err
+	}
+	*s = Square{js.X - 1, js.Y - 1, js.Size}
+	return nil
+}
+
+// ReadText parses a tiling in the format printed by the solver: an
+// optional line with the number of squares followed by one "x y size" line
+// per square, 1-based as in Square.String. Empty lines and lines starting
+// with "//" are ignored.
+func ReadText(r io.Reader) ([]Square, error) {
+	records, err := blocks.ReadText(r, "square", "x y size")
+	if err != nil {
+		return nil, err
+	}
+	squares := make([]Square, len(records))
+	for i, v := range records {
+		squares[i] = Square{v[0] - 1, v[1] - 1, v[2]}
+	}
+	return squares, nil
+}
+
+// WriteText writes squares in the format read by ReadText.
+func WriteText(w io.Writer, squares []Square) error {
+	return blocks.WriteText(w, squares)
+}
+
+func ReadJSON(r io.Reader) (*Tiling, error) {
+	t := &Tiling{}
+	if err := json.NewDecoder(r).Decode(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func WriteJSON(w io.Writer, t *Tiling) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(t)
+}
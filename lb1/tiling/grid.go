@@ -0,0 +1,145 @@
+package tiling
+
+const wordBits = 64
+
+// grid is the occupancy board. Each row is stored as a bitmask split into
+// 64-bit words, bit j of a row being set when column j is occupied.
+type grid struct {
+	rows, cols int
+	words      int
+	cells      []uint64
+}
+
+func initializeGrid(rows, cols int) *grid {
+	words := (cols + wordBits - 1) / wordBits
+	return &grid{rows: rows, cols: cols, words: words, cells: make([]uint64, rows*words)}
+}
+
+func (g *grid) clone() *grid {
+	c := *g
+	c.cells = append([]uint64{}, g.cells...)
+	return &c
+}
+
+func (g *grid) row(x int) []uint64 {
+	return g.cells[x*g.words : (x+1)*g.words]
+}
+
+func (g *grid) isOccupied(x, y int) bool {
+	return g.cells[x*g.words+y/wordBits]&(1<<(y%wordBits)) != 0
+}
+
+// segmentMask returns the bits of columns [y, y+size) that fall into word w.
+func segmentMask(w, y, size int) uint64 {
+	lo := Max(y, w*wordBits) - w*wordBits
+	hi := Min(y+size, (w+1)*wordBits) - w*wordBits
+	if lo >= hi {
+		return 0
+	}
+	return (^uint64(0) >> (wordBits - (hi - lo))) << lo
+}
+
+func ScaleSize(gridSize int) (int, int) {
+	maxDivisor := 1
+	for i := gridSize / 2; i >= 1; i-- {
+		if gridSize%i == 0 {
+			maxDivisor = i
+			break
+		}
+	}
+	return gridSize / maxDivisor, maxDivisor
+}
+
+// ScaleRect reduces an N×M board to a smaller one with the same optimal
+// tiling. Square boards are reduced with ScaleSize, rectangles by gcd(N, M).
+// It returns the reduced dimensions and the scale factor.
+func ScaleRect(N, M int) (int, int, int) {
+	if N == M {
+		gridSize, scale := ScaleSize(N)
+		return gridSize, gridSize, scale
+	}
+	g := gcd(N, M)
+	return N / g, M / g, g
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// board describes the (already scaled) area being tiled and the symmetry
+// breaking rules of the search.
+type board struct {
+	rows, cols int
+	// maxSide is the largest square the search may place. A square board
+	// must not be covered by a single square.
+	maxSide int
+	// cornerLimit forbids squares in the other three corners that are
+	// larger than the square in the top-left one. Every tiling of a
+	// rectangle can be reflected so that this holds.
+	cornerLimit bool
+	// base holds the blocked cells of the board, nil for an empty one.
+	base *grid
+	// sizes limits the squares the search may place.
+	sizes SizeRule
+	// perfect forbids two squares of the same size, maxDistinct above 0
+	// limits the number of different sizes.
+	perfect     bool
+	maxDistinct int
+	// coprime and faultFree are the quilt and fault-free rules, see
+	// board.rejects.
+	coprime   bool
+	faultFree bool
+	// pruning enables the lower bounds and candidate ordering of
+	// branch.search.
+	pruning bool
+	// scale is the factor the original board was divided by. It is only
+	// used to report events in the coordinates of the original board.
+	scale int
+}
+
+func newBoard(rows, cols int) *board {
+	b := &board{rows: rows, cols: cols, maxSide: Min(rows, cols), scale: 1}
+	if rows == cols {
+		b.maxSide = rows - 1
+	}
+	return b
+}
+
+// newGrid returns an occupancy grid with only the blocked cells set.
+func (b *board) newGrid() *grid {
+	if b.base == nil {
+		return initializeGrid(b.rows, b.cols)
+	}
+	return b.base.clone()
+}
+
+func (b *board) maxSize(x, y int) int {
+	maxSz := Min(Min(b.rows-x, b.cols-y), b.maxSide)
+	if b.sizes.Max > 0 {
+		maxSz = Min(maxSz, b.sizes.Max)
+	}
+	return maxSz
+}
+
+// allowed checks the size rules and the symmetry rules for a square placed
+// after current.
+func (b *board) allowed(x, y, size int, current []Square) bool {
+	if !b.sizes.Allows(size) {
+		return false
+	}
+	if b.distinct() && !b.distinctAllows(size, current) {
+		return false
+	}
+	if !b.cornerLimit || len(current) == 0 {
+		return true
+	}
+	right := y+size == b.cols
+	bottom := x+size == b.rows
+	if (x == 0 && right) || (y == 0 && bottom) || (right && bottom) {
+		return size <= current[0].Size
+	}
+	return true
+}
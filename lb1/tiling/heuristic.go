@@ -0,0 +1,292 @@
+package tiling
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"slices"
+	"sort"
+)
+
+// defaultHeuristicSteps is the number of local search moves when
+// Solver.HeuristicSteps is 0.
+const defaultHeuristicSteps = 500
+
+// repairNodes caps the nodes of the exact search that refills a hole.
+const repairNodes = 5000
+
+// pilotWidth is how many sizes below the largest fitting one the greedy
+// construction compares, besides a few fractions of it.
+const pilotWidth = 6
+
+// beamWidth is the number of partial tilings the construction keeps.
+const beamWidth = 32
+
+// ErrNoHeuristicTiling is returned when the greedy construction cannot
+// finish a tiling, which happens with restrictive size rules.
+var ErrNoHeuristicTiling = errors.New("heuristic found no tiling")
+
+// heuristic builds a tiling of a board in two stages. A beam search fills
+// the board cell by cell and ranks its partial tilings by how many squares
+// a plain greedy completion needs. A local search then rips out the
+// squares around a random window and refills the hole with a node-limited
+// exact search, keeping every change that does not add squares. The first
+// fixed squares never move.
+type heuristic struct {
+	board      *board
+	occupied   *grid
+	squares    []Square
+	fixed      int
+	rng        *rand.Rand
+	ctx        context.Context
+	iterations int
+}
+
+// largestAllowed returns the largest allowed square not above size, or 0.
+func (h *heuristic) largestAllowed(size int) int {
+	for size >= 1 && !h.board.sizes.Allows(size) {
+		size--
+	}
+	return size
+}
+
+// greedy fills g with a plain completion and returns its squares, or
+// false if it gets stuck. Every square is the largest that fits, or with
+// aligned the one whose bottom edge lines up with the occupied cells to
+// its left when there is such a square.
+func (h *heuristic) greedy(g *grid, from int, aligned bool) ([]Square, bool) {
+	bd := h.board
+	var squares []Square
+	for pos := g.findFirstFreePosition(from); pos != -1; pos = g.findFirstFreePosition(pos) {
+		x, y := pos/bd.cols, pos%bd.cols
+		fit := g.largestFit(x, y, bd.maxSize(x, y))
+		size := fit
+		if aligned {
+			if a := alignedSize(g, x, y); a > 0 && a < fit {
+				size = a
+			}
+		}
+		size = h.largestAllowed(size)
+		if size < 1 {
+			return nil, false
+		}
+		squares = append(squares, g.placeSquare(x, y, size))
+	}
+	return squares, true
+}
+
+// alignedSize returns the height of the occupied cells left of (x, y).
+func alignedSize(g *grid, x, y int) int {
+	a := 0
+	for y > 0 && x+a < g.rows && g.isOccupied(x+a, y-1) {
+		a++
+	}
+	return a
+}
+
+// partial is a partly tiled board kept by the beam of construct. score is
+// its square count plus the greedy estimate of the squares still needed.
+type partial struct {
+	occupied *grid
+	squares  []Square
+	from     int
+	score    int
+}
+
+// estimate returns the fewest squares the two greedy completions of g
+// need from the free cell from on, or -1 if both get stuck.
+func (h *heuristic) estimate(g *grid, from int) int {
+	best := -1
+	for _, aligned := range []bool{false, true} {
+		if squares, ok := h.greedy(g.clone(), from, aligned); ok && (best < 0 || len(squares) < best) {
+			best = len(squares)
+		}
+	}
+	return best
+}
+
+// construct fills the free cells with a beam search. At the first free
+// cell of every kept board it tries the sizes just below the largest that
+// fits, a few fractions of it and the one aligned with the squares to its
+// left, and keeps the beamWidth boards with the best score. When the
+// context is done the best board of the beam is completed greedily.
+func (h *heuristic) construct() bool {
+	bd := h.board
+	beam := []partial{{occupied: h.occupied, squares: h.squares}}
+	for {
+		var next []partial
+		growing := false
+		for _, p := range beam {
+			if h.ctx.Err() != nil {
+				return h.completeBest(beam)
+			}
+			pos := p.occupied.findFirstFreePosition(p.from)
+			if pos == -1 {
+				next = append(next, p)
+				continue
+			}
+			growing = true
+			x, y := pos/bd.cols, pos%bd.cols
+			fit := p.occupied.largestFit(x, y, bd.maxSize(x, y))
+			var sizes []int
+			for size := fit; size >= 1 && size > fit-pilotWidth; size-- {
+				sizes = append(sizes, size)
+			}
+			sizes = append(sizes, fit/2, fit/3, 2*fit/3, alignedSize(p.occupied, x, y))
+			for i, size := range sizes {
+				if size < 1 || size > fit || !bd.sizes.Allows(size) || slices.Contains(sizes[:i], size) {
+					continue
+				}
+				g := p.occupied.clone()
+				square := g.placeSquare(x, y, size)
+				count := h.estimate(g, pos)
+				if count < 0 {
+					continue
+				}
+				squares := append(append([]Square{}, p.squares...), square)
+				next = append(next, partial{occupied: g, squares: squares, from: pos, score: len(squares) + count})
+			}
+		}
+		if !growing || len(next) == 0 {
+			beam = next
+			break
+		}
+		sort.SliceStable(next, func(i, j int) bool { return next[i].score < next[j].score })
+		beam = next[:Min(len(next), beamWidth)]
+	}
+	if len(beam) == 0 {
+		return false
+	}
+	h.occupied, h.squares = beam[0].occupied, beam[0].squares
+	return true
+}
+
+// completeBest finishes the boards of a sorted beam with the greedy
+// completion, best score first, and keeps the first that gets through.
+func (h *heuristic) completeBest(beam []partial) bool {
+	for _, p := range beam {
+		for _, aligned := range []bool{false, true} {
+			g := p.occupied.clone()
+			if squares, ok := h.greedy(g, p.from, aligned); ok {
+				h.occupied = g
+				h.squares = append(append([]Square{}, p.squares...), squares...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// improve makes one local search move and reports whether it was kept.
+func (h *heuristic) improve() bool {
+	pick := h.squares[h.fixed+h.rng.Intn(len(h.squares)-h.fixed)]
+	r := 1 + h.rng.Intn(Max(3, pick.Size))
+	x0, y0 := pick.X-r, pick.Y-r
+	x1, y1 := pick.X+pick.Size+r, pick.Y+pick.Size+r
+
+	kept := append([]Square{}, h.squares[:h.fixed]...)
+	var removed []Square
+	for _, square := range h.squares[h.fixed:] {
+		if square.X < x1 && square.X+square.Size > x0 && square.Y < y1 && square.Y+square.Size > y0 {
+			removed = append(removed, square)
+			h.occupied.removeSquare(square)
+		} else {
+			kept = append(kept, square)
+		}
+	}
+
+	// The hole is searched as a board of its own, cut to the bounding box
+	// of the removed squares, with the kept squares around it blocked. A
+	// tiling with as many squares as before is accepted too, which lets
+	// the search drift.
+	hole, hx, hy := h.holeBoard(removed)
+	sh := newShared(h.ctx, 1, len(removed)+1)
+	b := newBranch(hole, sh, 0)
+	b.nodeLimit = repairNodes
+	b.search(hole.newGrid(), []Square{}, 0, 0)
+	h.iterations += b.iterations
+
+	if b.best == nil || len(b.best) > len(removed) {
+		for _, square := range removed {
+			h.occupied.placeSquare(square.X, square.Y, square.Size)
+		}
+		return false
+	}
+	for i, square := range b.best {
+		b.best[i] = h.occupied.placeSquare(square.X+hx, square.Y+hy, square.Size)
+	}
+	h.squares = append(kept, b.best...)
+	return true
+}
+
+// holeBoard returns the board of the bounding box of removed and the
+// offset of its top-left cell. Cells of the box that are still occupied are
+// blocked.
+func (h *heuristic) holeBoard(removed []Square) (*board, int, int) {
+	x0, y0 := h.board.rows, h.board.cols
+	x1, y1 := 0, 0
+	for _, square := range removed {
+		x0, y0 = Min(x0, square.X), Min(y0, square.Y)
+		x1, y1 = Max(x1, square.X+square.Size), Max(y1, square.Y+square.Size)
+	}
+	rows, cols := x1-x0, y1-y0
+	base := initializeGrid(rows, cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if h.occupied.isOccupied(x0+i, y0+j) {
+				base.placeSquare(i, j, 1)
+			}
+		}
+	}
+	hole := &board{
+		rows: rows, cols: cols,
+		maxSide: Min(Min(rows, cols), h.board.maxSide),
+		base:    base, sizes: h.board.sizes, pruning: true, scale: 1,
+	}
+	return hole, x0, y0
+}
+
+// searchHeuristic tiles bd around the initial squares with the heuristic
+// and returns the best tiling found, checked with the verifier.
+func (s *Solver) searchHeuristic(ctx context.Context, bd *board, occupied *grid, initialSquares []Square) ([]Square, int, error) {
+	h := &heuristic{
+		board:    bd,
+		occupied: occupied.clone(),
+		squares:  append([]Square{}, initialSquares...),
+		fixed:    len(initialSquares),
+		rng:      rand.New(rand.NewSource(s.Seed)),
+		ctx:      ctx,
+	}
+	if !h.construct() {
+		return nil, 0, ErrNoHeuristicTiling
+	}
+
+	steps := s.HeuristicSteps
+	if steps <= 0 {
+		steps = defaultHeuristicSteps
+	}
+	for step := 0; step < steps && len(h.squares) > h.fixed; step++ {
+		select {
+		case <-ctx.Done():
+			return h.squares, h.iterations, bd.verify(h.squares)
+		default:
+		}
+		h.improve()
+	}
+	return h.squares, h.iterations, bd.verify(h.squares)
+}
+
+// verify checks a tiling of the board with the verifier.
+func (b *board) verify(squares []Square) error {
+	l := &Layout{Rows: b.rows, Cols: b.cols}
+	if b.base != nil {
+		l.Blocked = make([][]bool, b.rows)
+		for i := range l.Blocked {
+			l.Blocked[i] = make([]bool, b.cols)
+			for j := range l.Blocked[i] {
+				l.Blocked[i][j] = b.base.isOccupied(i, j)
+			}
+		}
+	}
+	return VerifyLayout(l, squares)
+}
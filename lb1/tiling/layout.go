@@ -0,0 +1,155 @@
+package tiling
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Layout is a board with forbidden cells and squares that are already
+// fixed in place.
+type Layout struct {
+	Rows, Cols int
+	Blocked    [][]bool
+	Fixed      []Square
+}
+
+// ParseLayout reads a layout in the following text format:
+//
+//	N M
+//	N lines of M characters, '.' for a free cell and '#' for a blocked one
+//	optional "x y size" lines with fixed squares, 1-based as in Square.String
+//
+// Empty lines and lines starting with "//" are ignored.
+func ParseLayout(r io.Reader) (*Layout, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("empty layout")
+	}
+
+	l := &Layout{}
+	if _, err := fmt.Sscan(lines[0], &l.Rows, &l.Cols); err != nil {
+		return nil, fmt.Errorf("line 1: expected \"N M\": %v", err)
+	}
+	if l.Rows <= 0 || l.Cols <= 0 {
+		return nil, fmt.Errorf("line 1: board size must be positive")
+	}
+	if len(lines) < 1+l.Rows {
+		return nil, fmt.Errorf("expected %d mask lines, got %d", l.Rows, len(lines)-1)
+	}
+
+	l.Blocked = make([][]bool, l.Rows)
+	for i := 0; i < l.Rows; i++ {
+		row := lines[1+i]
+		if len(row) != l.Cols {
+			return nil, fmt.Errorf("mask row %d: expected %d cells, got %d", i+1, l.Cols, len(row))
+		}
+		l.Blocked[i] = make([]bool, l.Cols)
+		for j, c := range row {
+			switch c {
+			case '.':
+			case '#':
+				l.Blocked[i][j] = true
+			default:
+				return nil, fmt.Errorf("mask row %d: unexpected character %q", i+1, c)
+			}
+		}
+	}
+
+	for _, line := range lines[1+l.Rows:] {
+		var square Square
+		if _, err := fmt.Sscan(line, &square.X, &square.Y, &square.Size); err != nil {
+			return nil, fmt.Errorf("fixed square %q: %v", line, err)
+		}
+		square.X--
+		square.Y--
+		l.Fixed = append(l.Fixed, square)
+	}
+	return l, l.validate()
+}
+
+func (l *Layout) validate() error {
+	g := l.blockedGrid()
+	for _, square := range l.Fixed {
+		if square.Size < 1 || square.X < 0 || square.Y < 0 ||
+			square.X+square.Size > l.Rows || square.Y+square.Size > l.Cols {
+			return fmt.Errorf("fixed square %s is outside the board", square)
+		}
+		if !g.canPlace(square.X, square.Y, square.Size) {
+			return fmt.Errorf("fixed square %s overlaps a blocked cell or another square", square)
+		}
+		g.placeSquare(square.X, square.Y, square.Size)
+	}
+	return nil
+}
+
+// Plain reports whether the layout is an empty rectangle, in which case
+// the usual shortcuts of SolveRect apply.
+func (l *Layout) Plain() bool {
+	if len(l.Fixed) > 0 {
+		return false
+	}
+	for _, row := range l.Blocked {
+		for _, blocked := range row {
+			if blocked {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (l *Layout) blockedGrid() *grid {
+	g := initializeGrid(l.Rows, l.Cols)
+	for i, row := range l.Blocked {
+		for j, blocked := range row {
+			if blocked {
+				g.placeSquare(i, j, 1)
+			}
+		}
+	}
+	return g
+}
+
+// SolveLayout finds the minimum tiling of the free cells of a layout. The
+// result includes the fixed squares, and so does s.MinSquares. Scaling,
+// the initial corner squares and the rectangle symmetry rules assume an
+// empty board, so they are only used when the layout is plain. Fixed
+// squares do not have to follow s.Sizes.
+func (s *Solver) SolveLayout(ctx context.Context, l *Layout) ([]Square, error) {
+	if l.Rows < 1 || l.Cols < 1 {
+		s.Reset()
+		return s.BestResult, fmt.Errorf("%w, got %dx%d", ErrInvalidBoard, l.Rows, l.Cols)
+	}
+	if l.Blocked != nil && !blockedFits(l) {
+		s.Reset()
+		return s.BestResult, fmt.Errorf("blocked cells do not match the %dx%d board", l.Rows, l.Cols)
+	}
+	if l.Plain() {
+		return s.SolveRect(ctx, l.Rows, l.Cols)
+	}
+	s.Reset()
+	if s.Resume != nil {
+		return nil, fmt.Errorf("%w: only plain boards can be resumed", ErrCheckpointMismatch)
+	}
+	bd := &board{rows: l.Rows, cols: l.Cols, maxSide: Min(l.Rows, l.Cols), base: l.blockedGrid(), sizes: s.Sizes, perfect: s.Perfect, maxDistinct: s.DistinctSizes, coprime: s.Coprime, faultFree: s.FaultFree, pruning: s.Pruning, scale: 1}
+	occupied := bd.newGrid()
+	for _, square := range l.Fixed {
+		occupied.placeSquare(square.X, square.Y, square.Size)
+	}
+	err := s.run(ctx, bd, occupied, append([]Square{}, l.Fixed...), nil)
+	return s.BestResult, err
+}
@@ -0,0 +1,68 @@
+package tiling
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSolveLayout(t *testing.T) {
+	tests := []struct {
+		name, layout string
+		squares      int
+	}{
+		{"center-blocked", "3 3\n...\n.#.\n...\n", 8},
+		{"corner-blocked", "5 5\n#....\n.....\n.....\n.....\n.....\n", 7},
+		{"hole", "6 6\n......\n......\n..##..\n..##..\n......\n......\n", 8},
+		{"fixed-corner", "4 4\n....\n....\n....\n....\n1 1 2\n", 4},
+		{"fixed-center", "5 5\n.....\n.....\n.....\n.....\n.....\n2 2 3\n", 17},
+		{"fixed-rectangle", "4 6\n......\n......\n......\n......\n1 1 4\n", 3},
+		{"fixed-unit", "3 4\n....\n....\n....\n1 1 1\n", 4},
+		{"plain", "// no blocked cells or fixed squares\n5 5\n.....\n.....\n.....\n.....\n.....\n", 8},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := ParseLayout(strings.NewReader(tc.layout))
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := NewSolver()
+			squares, err := s.SolveLayout(context.Background(), l)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !s.Optimal {
+				t.Fatal("the search did not finish")
+			}
+			if err := VerifyLayout(l, squares); err != nil {
+				t.Fatal(err)
+			}
+			if len(squares) != tc.squares || s.MinSquares != tc.squares {
+				t.Errorf("got %d squares, want %d", len(squares), tc.squares)
+			}
+			for _, fixed := range l.Fixed {
+				if !slices.Contains(squares, fixed) {
+					t.Errorf("the fixed square %s is missing from %v", fixed, squares)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLayoutErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"0 3\n",
+		"2 2\n..\n",
+		"2 2\n..\n.x\n",
+		"2 3\n..\n...\n",
+		"2 2\n..\n..\n2 2 2\n",
+		"2 2\n#.\n..\n1 1 1\n",
+		"3 3\n...\n...\n...\n1 1 2\n2 2 2\n",
+	} {
+		if _, err := ParseLayout(strings.NewReader(text)); err == nil {
+			t.Errorf("%q: no error", text)
+		}
+	}
+}
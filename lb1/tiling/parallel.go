@@ -0,0 +1,143 @@
+package tiling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// tasksPerWorker controls how finely the top of the search tree is split.
+// Subtrees differ a lot in size, so a few tasks per worker keep the pool busy.
+const tasksPerWorker = 8
+
+// ErrWorkerPanic is returned when a goroutine of the parallel search
+// panics. A panic there cannot be recovered by the caller of Solve, so the
+// pool recovers it, stops the other workers and reports it instead.
+var ErrWorkerPanic = errors.New("parallel search worker panicked")
+
+// taskHook, when set by a test, runs before every task of the pool.
+var taskHook func(task []Square)
+
+// searchParallel splits the tree below the initial squares into subtrees
+// and searches them on a pool of s.Workers goroutines sharing one bound,
+// which starts at bound squares.
+func (s *Solver) searchParallel(ctx context.Context, bd *board, initialSquares []Square, bound int) (*shared, error) {
+	tasks, expanded := splitTasks(bd, initialSquares, s.Workers*tasksPerWorker)
+	if len(tasks) == 0 {
+		s.Iterations = expanded
+		return newShared(ctx, 1, bound), nil
+	}
+	sh := newShared(ctx, int64(len(tasks)), bound)
+
+	branches := make([]*branch, len(tasks))
+	for i := range tasks {
+		branches[i] = newBranch(bd, sh, int64(i))
+	}
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	var panicOnce sync.Once
+	var panicErr error
+	for w := 0; w < s.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// A worker keeps draining the queue after a panic, the tasks
+			// left just return at once from the stopped search.
+			for i := range queue {
+				if err := runTask(branches[i], bd, tasks[i]); err != nil {
+					panicOnce.Do(func() { panicErr = err })
+					sh.stopped.Store(true)
+				}
+			}
+		}()
+	}
+	for i := range tasks {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	if panicErr != nil {
+		return nil, panicErr
+	}
+
+	s.Iterations = expanded
+	for _, b := range branches {
+		s.Iterations += b.iterations
+	}
+	_, winner := sh.decode(sh.bound.Load())
+	if best := branches[winner].best; best != nil {
+		s.MinSquares, s.BestResult = len(best), best
+	}
+	return sh, nil
+}
+
+// runTask searches the subtree of task with b and turns a panic into an
+// error wrapping ErrWorkerPanic.
+func runTask(b *branch, bd *board, task []Square) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrWorkerPanic, r)
+		}
+	}()
+	if !b.improves(len(task)) || b.stopped() {
+		return nil
+	}
+	if taskHook != nil {
+		taskHook(task)
+	}
+	occupied := bd.newGrid()
+	for _, square := range task {
+		occupied.placeSquare(square.X, square.Y, square.Size)
+	}
+	b.search(occupied, task, 0, 0)
+	return nil
+}
+
+// splitTasks expands the search tree level by level until there are at
+// least limit partial tilings. Dead ends, where no allowed square fits the
+// free cell, are dropped. The children of every node are generated in
+// the same order as in branch.search, so the returned slice follows the
+// sequential traversal order. The second result is the number of expanded
+// nodes.
+func splitTasks(bd *board, initialSquares []Square, limit int) ([][]Square, int) {
+	tasks := [][]Square{initialSquares}
+	expanded := 0
+
+	for len(tasks) < limit {
+		next := [][]Square{}
+		grown := false
+		for _, task := range tasks {
+			occupied := bd.newGrid()
+			for _, square := range task {
+				occupied.placeSquare(square.X, square.Y, square.Size)
+			}
+			pos := occupied.findFirstFreePosition(0)
+			if pos == -1 {
+				next = append(next, task)
+				continue
+			}
+
+			expanded++
+			grown = true
+			x, y := pos/bd.cols, pos%bd.cols
+			maxSz := bd.maxSize(x, y)
+			if bd.pruning {
+				maxSz = occupied.largestFit(x, y, maxSz)
+			}
+			var buf [64]int
+			for _, size := range bd.candidates(occupied, x, y, maxSz, buf[:0]) {
+				if bd.allowed(x, y, size, task) && occupied.canPlace(x, y, size) {
+					child := append(append([]Square{}, task...), Square{x, y, size})
+					next = append(next, child)
+				}
+			}
+		}
+		if !grown {
+			break
+		}
+		tasks = next
+	}
+	return tasks, expanded
+}
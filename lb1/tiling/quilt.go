@@ -0,0 +1,105 @@
+package tiling
+
+// A tiling is a Mrs. Perkins's quilt when the gcd of its sizes is 1, so
+// that it is not a scaled copy of a smaller tiling. It is fault-free when
+// no straight grid line crosses the whole board without cutting a square.
+
+// constrained reports whether the board has rules that only the plain
+// backtracking search checks.
+func (b *board) constrained() bool {
+	return b.distinct() || b.coprime || b.faultFree
+}
+
+// rejects reports whether current, whose first free cell is pos (-1 for
+// a full board), breaks the quilt or fault-free rule or can no longer
+// satisfy it. from is the free cell of the parent node, the lines above
+// its row were checked there.
+func (b *board) rejects(occupied *grid, current []Square, from, pos int) bool {
+	if !b.coprime && !b.faultFree {
+		return false
+	}
+	x := b.rows
+	if pos != -1 {
+		x = pos / b.cols
+	}
+	if b.coprime && !b.coprimeReachable(current, x, pos == -1) {
+		return true
+	}
+	if !b.faultFree {
+		return false
+	}
+
+	// Squares are placed at the first free cell, so no later square has
+	// its top row above x and the horizontal lines up to x are final.
+	fromRow := from / b.cols
+	for r := fromRow + 1; r <= x && r < b.rows; r++ {
+		if !crossesRow(current, r) {
+			return true
+		}
+	}
+	if x == fromRow && pos != -1 {
+		return false
+	}
+	// A vertical line that no square cuts yet needs a later square over
+	// two free cells on both sides of it.
+	for c := 1; c < b.cols; c++ {
+		if !crossesCol(current, c) && (pos == -1 || !occupied.freePairFrom(x, c)) {
+			return true
+		}
+	}
+	return false
+}
+
+// coprimeReachable reports whether the sizes of current can still end up
+// with a gcd of 1. Later squares have their top row at x or below, so the
+// sizes they may take are the allowed ones up to the side left below x.
+func (b *board) coprimeReachable(current []Square, x int, full bool) bool {
+	g := 0
+	for _, square := range current {
+		g = gcd(g, square.Size)
+	}
+	if full || g == 1 {
+		return g == 1
+	}
+	side := Min(Min(b.rows-x, b.cols), b.maxSide)
+	if b.sizes.Max > 0 {
+		side = Min(side, b.sizes.Max)
+	}
+	for size := 1; size <= side && g != 1; size++ {
+		if b.sizes.Allows(size) {
+			g = gcd(g, size)
+		}
+	}
+	return g == 1
+}
+
+// crossesRow reports whether a square cuts the line above row r.
+func crossesRow(squares []Square, r int) bool {
+	for _, square := range squares {
+		if square.X < r && r < square.X+square.Size {
+			return true
+		}
+	}
+	return false
+}
+
+// crossesCol reports whether a square cuts the line left of column c.
+func crossesCol(squares []Square, c int) bool {
+	for _, square := range squares {
+		if square.Y < c && c < square.Y+square.Size {
+			return true
+		}
+	}
+	return false
+}
+
+// freePairFrom reports whether some row from x down has free cells on
+// both sides of the line left of column c.
+func (g *grid) freePairFrom(x, c int) bool {
+	for r := x; r < g.rows; r++ {
+		if !g.isOccupied(r, c-1) && !g.isOccupied(r, c) {
+			return true
+		}
+	}
+	return false
+}
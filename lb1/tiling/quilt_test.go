@@ -0,0 +1,80 @@
+package tiling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// quiltOptima are the sizes of the smallest Mrs. Perkins's quilts of the
+// N×N boards, from N=2 on.
+var quiltOptima = []int{4, 6, 7, 8, 9, 9, 10, 10, 11}
+
+func TestSolveQuilt(t *testing.T) {
+	for i, want := range quiltOptima {
+		n := i + 2
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			s := NewSolver()
+			s.Coprime = true
+			squares := solveChecked(t, s, n)
+			if len(squares) != want {
+				t.Errorf("got %d squares, want %d", len(squares), want)
+			}
+			g := 0
+			for _, square := range squares {
+				g = gcd(g, square.Size)
+			}
+			if g != 1 {
+				t.Errorf("the sizes %s have a gcd of %d", FormatSizes(squares), g)
+			}
+		})
+	}
+}
+
+func TestSolveFaultFree(t *testing.T) {
+	tests := []struct {
+		rows, cols int
+		// squares is 0 when the board has no fault-free tiling.
+		squares int
+	}{
+		{2, 2, 0}, {3, 3, 0}, {4, 4, 0},
+		{5, 5, 13}, {6, 6, 11}, {7, 7, 9}, {8, 8, 10},
+		{5, 6, 10},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%dx%d", tc.rows, tc.cols), func(t *testing.T) {
+			s := NewSolver()
+			s.FaultFree = true
+			squares, err := s.SolveRect(context.Background(), tc.rows, tc.cols)
+			if tc.squares == 0 {
+				if !errors.Is(err, ErrInfeasible) {
+					t.Errorf("got %v, %v, want ErrInfeasible", squares, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !s.Optimal {
+				t.Fatal("the search did not finish")
+			}
+			if err := Verify(tc.rows, tc.cols, squares); err != nil {
+				t.Fatal(err)
+			}
+			if len(squares) != tc.squares {
+				t.Errorf("got %d squares, want %d", len(squares), tc.squares)
+			}
+			for r := 1; r < tc.rows; r++ {
+				if !crossesRow(squares, r) {
+					t.Errorf("no square cuts the line above row %d", r)
+				}
+			}
+			for c := 1; c < tc.cols; c++ {
+				if !crossesCol(squares, c) {
+					t.Errorf("no square cuts the line left of column %d", c)
+				}
+			}
+		})
+	}
+}
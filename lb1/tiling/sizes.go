@@ -0,0 +1,191 @@
+package tiling
+
+import (
+	"awesomeProject2/internal/blocks"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInfeasible is returned when no tiling satisfies the size rule.
+var ErrInfeasible = errors.New("no tiling with the allowed square sizes exists")
+
+// SizeRule limits the sizes of the squares the solver may place. The zero
+// value allows every size.
+type SizeRule struct {
+	// Min and Max bound the side of a square, 0 means no bound.
+	Min, Max int
+	// Allowed is a whitelist of sides, empty means every side.
+	Allowed []int
+}
+
+// NoUnitSquares forbids 1×1 squares.
+func NoUnitSquares() SizeRule {
+	return SizeRule{Min: 2}
+}
+
+// Empty reports whether the rule allows every size.
+func (r SizeRule) Empty() bool {
+	return r.Min <= 1 && r.Max == 0 && len(r.Allowed) == 0
+}
+
+// Allows reports whether a square of the given side satisfies the rule.
+func (r SizeRule) Allows(size int) bool {
+	if size < r.Min || (r.Max > 0 && size > r.Max) {
+		return false
+	}
+	if len(r.Allowed) == 0 {
+		return true
+	}
+	for _, allowed := range r.Allowed {
+		if allowed == size {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the rule is well formed and lets some square fit
+// a rows×cols board. A square board does not count as a square of its own.
+func (r SizeRule) Validate(rows, cols int) error {
+	if r.Min < 0 || r.Max < 0 {
+		return fmt.Errorf("square size bounds must not be negative")
+	}
+	if r.Max > 0 && r.Min > r.Max {
+		return fmt.Errorf("smallest square size %d is above the largest %d", r.Min, r.Max)
+	}
+	for _, size := range r.Allowed {
+		if size < 1 {
+			return fmt.Errorf("invalid square size %d", size)
+		}
+	}
+	largest := Min(rows, cols)
+	if rows == cols {
+		largest--
+	}
+	for size := 1; size <= largest; size++ {
+		if r.Allows(size) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no allowed square size fits the %dx%d board", rows, cols)
+}
+
+// ParseSizes reads a comma separated whitelist of sizes such as "2,3,5".
+func ParseSizes(list string) ([]int, error) {
+	var sizes []int
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		size, err := strconv.Atoi(field)
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("invalid square size %q", field)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// shortcuts reports whether scaling and the initial corner squares may be
+// used. They rely on every size being allowed any number of times and on
+// no rule about the tiling as a whole.
+func (s *Solver) shortcuts() bool {
+	return s.Sizes.Empty() && !s.Perfect && s.DistinctSizes == 0 && !s.Coprime && !s.FaultFree
+}
+
+// distinct reports whether the board limits the number of square sizes.
+func (b *board) distinct() bool {
+	return b.perfect || b.maxDistinct > 0
+}
+
+// usedSizes appends the different sizes of current to buf.
+func usedSizes(current []Square, buf []int) []int {
+	for _, square := range current {
+		if !slices.Contains(buf, square.Size) {
+			buf = append(buf, square.Size)
+		}
+	}
+	return buf
+}
+
+// distinctAllows reports whether a square of the given size may follow
+// current under the limits on the number of sizes.
+func (b *board) distinctAllows(size int, current []Square) bool {
+	var buf [32]int
+	used := usedSizes(current, buf[:0])
+	if slices.Contains(used, size) {
+		return !b.perfect
+	}
+	return b.perfect || len(used) < b.maxDistinct
+}
+
+// distinctBound returns the fewest squares no larger than side that can
+// cover free cells after current under the limits on the number of sizes,
+// or initialBound if they cannot. In a perfect tiling every new square has
+// an unused size, so the largest unused sizes give the bound. Once all
+// allowed sizes are in use only the largest used one that fits counts.
+func (b *board) distinctBound(free, side int, current []Square) int {
+	var buf [32]int
+	used := usedSizes(current, buf[:0])
+	if b.perfect {
+		count := 0
+		for size := side; size >= 1 && free > 0; size-- {
+			if b.sizes.Allows(size) && !slices.Contains(used, size) {
+				free -= size * size
+				count++
+			}
+		}
+		if free > 0 {
+			return initialBound
+		}
+		return count
+	}
+	if len(used) < b.maxDistinct {
+		return 0
+	}
+	largest := 0
+	for _, size := range used {
+		if size <= side && size > largest {
+			largest = size
+		}
+	}
+	if largest == 0 {
+		return initialBound
+	}
+	return blocks.CeilDiv(free, largest*largest)
+}
+
+// SizeMultiset returns the sizes of the squares, largest first.
+func SizeMultiset(squares []Square) []int {
+	sizes := make([]int, len(squares))
+	for i, square := range squares {
+		sizes[i] = square.Size
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return sizes
+}
+
+// FormatSizes writes the size multiset of squares such as "{5, 3×2, 1×4}",
+// a size that occurs more than once followed by its count.
+func FormatSizes(squares []Square) string {
+	sizes := SizeMultiset(squares)
+	var parts []string
+	for i := 0; i < len(sizes); {
+		j := i
+		for j < len(sizes) && sizes[j] == sizes[i] {
+			j++
+		}
+		if j-i == 1 {
+			parts = append(parts, strconv.Itoa(sizes[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d×%d", sizes[i], j-i))
+		}
+		i = j
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
@@ -0,0 +1,147 @@
+package tiling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSolveSizeRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       SizeRule
+		rows, cols int
+		// squares is 0 when no tiling follows the rule.
+		squares int
+	}{
+		{"no-unit", NoUnitSquares(), 3, 3, 0},
+		{"no-unit", NoUnitSquares(), 5, 5, 0},
+		{"no-unit", NoUnitSquares(), 6, 6, 4},
+		{"no-unit", NoUnitSquares(), 4, 6, 3},
+		{"max-1", SizeRule{Max: 1}, 4, 4, 16},
+		{"max-3", SizeRule{Max: 3}, 7, 7, 12},
+		{"min-2-max-3", SizeRule{Min: 2, Max: 3}, 6, 6, 4},
+		{"allowed-3", SizeRule{Allowed: []int{3}}, 6, 6, 4},
+		{"allowed-2-3", SizeRule{Allowed: []int{2, 3}}, 7, 7, 0},
+		{"allowed-1-2", SizeRule{Allowed: []int{1, 2}}, 5, 5, 13},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%s/%dx%d", tc.name, tc.rows, tc.cols), func(t *testing.T) {
+			s := NewSolver()
+			s.Sizes = tc.rule
+			squares, err := s.SolveRect(context.Background(), tc.rows, tc.cols)
+			if tc.squares == 0 {
+				if !errors.Is(err, ErrInfeasible) {
+					t.Errorf("got %v, %v, want ErrInfeasible", squares, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !s.Optimal {
+				t.Fatal("the search did not finish")
+			}
+			if err := Verify(tc.rows, tc.cols, squares); err != nil {
+				t.Fatal(err)
+			}
+			if len(squares) != tc.squares {
+				t.Errorf("got %d squares, want %d", len(squares), tc.squares)
+			}
+			for _, square := range squares {
+				if !tc.rule.Allows(square.Size) {
+					t.Errorf("square %s breaks the rule", square)
+				}
+			}
+		})
+	}
+}
+
+func TestSizeRuleValidate(t *testing.T) {
+	tests := []struct {
+		rule       SizeRule
+		rows, cols int
+		valid      bool
+	}{
+		{SizeRule{}, 5, 5, true},
+		{SizeRule{Min: 5}, 5, 5, false},
+		{SizeRule{Min: 5}, 5, 6, true},
+		{SizeRule{Min: -1}, 5, 5, false},
+		{SizeRule{Min: 3, Max: 2}, 5, 5, false},
+		{SizeRule{Allowed: []int{0}}, 5, 5, false},
+		{SizeRule{Allowed: []int{7}}, 5, 5, false},
+	}
+	for _, tc := range tests {
+		if err := tc.rule.Validate(tc.rows, tc.cols); (err == nil) != tc.valid {
+			t.Errorf("%+v on %dx%d: got %v, want valid %v", tc.rule, tc.rows, tc.cols, err, tc.valid)
+		}
+	}
+}
+
+func TestParseSizes(t *testing.T) {
+	sizes, err := ParseSizes(" 2, 3,,5 ")
+	if err != nil || !slices.Equal(sizes, []int{2, 3, 5}) {
+		t.Errorf("got %v, %v, want [2 3 5]", sizes, err)
+	}
+	for _, list := range []string{"2,x", "0", "-3"} {
+		if _, err := ParseSizes(list); err == nil {
+			t.Errorf("%q: no error", list)
+		}
+	}
+}
+
+func TestSolvePerfectAndDistinct(t *testing.T) {
+	tests := []struct {
+		name       string
+		perfect    bool
+		distinct   int
+		rows, cols int
+		squares    int
+	}{
+		{"perfect", true, 0, 5, 5, 0},
+		{"perfect", true, 0, 3, 4, 0},
+		// The smallest perfect rectangle, found by Moroń.
+		{"perfect", true, 0, 32, 33, 9},
+		{"distinct-1", false, 1, 5, 5, 25},
+		{"distinct-1", false, 1, 6, 6, 4},
+		{"distinct-2", false, 2, 5, 5, 10},
+		{"distinct-2", false, 2, 7, 7, 14},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%s/%dx%d", tc.name, tc.rows, tc.cols), func(t *testing.T) {
+			s := NewSolver()
+			s.Perfect, s.DistinctSizes = tc.perfect, tc.distinct
+			squares, err := s.SolveRect(context.Background(), tc.rows, tc.cols)
+			if tc.squares == 0 {
+				if !errors.Is(err, ErrInfeasible) {
+					t.Errorf("got %v, %v, want ErrInfeasible", squares, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !s.Optimal {
+				t.Fatal("the search did not finish")
+			}
+			if err := Verify(tc.rows, tc.cols, squares); err != nil {
+				t.Fatal(err)
+			}
+			if len(squares) != tc.squares {
+				t.Errorf("got %d squares, want %d", len(squares), tc.squares)
+			}
+			sizes := map[int]bool{}
+			for _, square := range squares {
+				sizes[square.Size] = true
+			}
+			if tc.perfect && len(sizes) != len(squares) {
+				t.Errorf("sizes %s repeat in a perfect tiling", FormatSizes(squares))
+			}
+			if tc.distinct > 0 && len(sizes) > tc.distinct {
+				t.Errorf("got %d different sizes, want at most %d", len(sizes), tc.distinct)
+			}
+		})
+	}
+}
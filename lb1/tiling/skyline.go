@@ -0,0 +1,287 @@
+package tiling
+
+import (
+	"awesomeProject2/internal/blocks"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Engine selects the search algorithm used by a Solver.
+type Engine int
+
+const (
+	// Backtracking is the branch-and-bound search over the occupancy grid.
+	Backtracking Engine = iota
+	// Skyline searches over column profiles and caches the optimal
+	// completion of every profile it has solved.
+	Skyline
+	// Heuristic builds a tiling with a beam search and improves it by
+	// local search. It is fast on large boards but proves nothing.
+	Heuristic
+	// DLX solves the board as an exact cover problem with Algorithm X on
+	// dancing links, looking for the cover with the fewest squares.
+	DLX
+)
+
+var engineNames = []string{"backtracking", "skyline", "heuristic", "dlx"}
+
+func (e Engine) String() string {
+	if int(e) < len(engineNames) {
+		return engineNames[e]
+	}
+	return fmt.Sprintf("Engine(%d)", int(e))
+}
+
+func ParseEngine(name string) (Engine, error) {
+	for i, engineName := range engineNames {
+		if engineName == name {
+			return Engine(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown engine %q", name)
+}
+
+// ErrNotSkyline is returned by the skyline engine for boards whose filled
+// cells do not form a profile, such as boards with blocked cells.
+var ErrNotSkyline = errors.New("skyline engine needs a board without holes")
+
+// maxProfileEntries caps the transposition table unless
+// Solver.ProfileEntries sets another cap. Once it is full the search goes
+// on without caching new profiles.
+const maxProfileEntries = 1 << 22
+
+// profileEntry is what the transposition table knows about a profile:
+// either the exact number of squares needed to complete it, together with
+// the size of the first square of such a completion, or a lower bound.
+type profileEntry struct {
+	value int
+	exact bool
+	move  int
+}
+
+// skyline describes the filled part of the board by the height of every
+// column. Squares are always placed at the leftmost lowest column, which is
+// the first free cell in row-major order, so the filled region stays a
+// staircase and two searches reaching the same heights share the rest.
+type skyline struct {
+	board      *board
+	heights    []byte
+	memo       map[string]*profileEntry
+	maxEntries int
+	shared     *shared
+	iterations int
+}
+
+func newSkyline(bd *board, occupied *grid, sh *shared, maxEntries int) (*skyline, error) {
+	if bd.rows > 255 {
+		return nil, fmt.Errorf("skyline engine supports at most 255 rows")
+	}
+	sk := &skyline{board: bd, heights: make([]byte, bd.cols), memo: map[string]*profileEntry{}, maxEntries: maxEntries, shared: sh}
+	for j := 0; j < bd.cols; j++ {
+		h := 0
+		for h < bd.rows && occupied.isOccupied(h, j) {
+			h++
+		}
+		for x := h; x < bd.rows; x++ {
+			if occupied.isOccupied(x, j) {
+				return nil, ErrNotSkyline
+			}
+		}
+		sk.heights[j] = byte(h)
+	}
+	return sk, nil
+}
+
+// lowest returns the first free cell, or -1 for x when the board is full.
+func (sk *skyline) lowest() (int, int) {
+	x, y := sk.board.rows, -1
+	for j, h := range sk.heights {
+		if int(h) < x {
+			x, y = int(h), j
+		}
+	}
+	if y == -1 {
+		return -1, -1
+	}
+	return x, y
+}
+
+func (sk *skyline) stopped() bool {
+	if sk.iterations%blocks.CancelCheckInterval == 0 {
+		select {
+		case <-sk.shared.done:
+			sk.shared.stopped.Store(true)
+		default:
+		}
+	}
+	return sk.shared.stopped.Load()
+}
+
+func (sk *skyline) raise(y, size, delta int) {
+	for j := y; j < y+size; j++ {
+		sk.heights[j] = byte(int(sk.heights[j]) + delta)
+	}
+}
+
+// solve returns the fewest squares that complete the current profile if
+// that number is at most budget.
+func (sk *skyline) solve(budget int) (int, bool) {
+	sk.iterations++
+	if sk.stopped() {
+		return 0, false
+	}
+	x, y := sk.lowest()
+	if x == -1 {
+		return 0, true
+	}
+	if budget <= 0 {
+		return 0, false
+	}
+
+	key := string(sk.heights)
+	entry := sk.memo[key]
+	if entry != nil {
+		if entry.exact {
+			return entry.value, entry.value <= budget
+		}
+		if entry.value > budget {
+			return 0, false
+		}
+	}
+
+	free := 0
+	for _, h := range sk.heights {
+		free += sk.board.rows - int(h)
+	}
+	side := Min(Min(sk.board.rows-x, sk.board.cols), sk.board.maxSide)
+	if sk.board.sizes.Max > 0 {
+		side = Min(side, sk.board.sizes.Max)
+	}
+	if side < 1 {
+		return 0, false
+	}
+	if blocks.CeilDiv(free, side*side) > budget {
+		sk.store(key, entry, &profileEntry{value: blocks.CeilDiv(free, side*side)})
+		return 0, false
+	}
+
+	run := 0
+	for y+run < sk.board.cols && int(sk.heights[y+run]) == x {
+		run++
+	}
+	best, move := budget+1, 0
+	for size := Min(sk.board.maxSize(x, y), run); size >= 1 && best > 1; size-- {
+		if !sk.board.sizes.Allows(size) {
+			continue
+		}
+		sk.raise(y, size, size)
+		value, ok := sk.solve(best - 2)
+		sk.raise(y, size, -size)
+		if sk.shared.stopped.Load() {
+			return 0, false
+		}
+		if ok && value+1 < best {
+			best, move = value+1, size
+		}
+	}
+
+	if move == 0 {
+		sk.store(key, entry, &profileEntry{value: budget + 1})
+		return 0, false
+	}
+	sk.store(key, entry, &profileEntry{value: best, exact: true, move: move})
+	return best, true
+}
+
+// store records what was learnt about a profile, keeping the stronger of
+// the old and the new lower bound.
+func (sk *skyline) store(key string, old, entry *profileEntry) {
+	if old != nil {
+		if !entry.exact && old.value >= entry.value {
+			return
+		}
+		*old = *entry
+		return
+	}
+	if len(sk.memo) < sk.maxEntries {
+		sk.memo[key] = entry
+	}
+}
+
+// trace rebuilds the squares of the optimal completion of the current
+// profile from the moves stored in the table. The move of a profile missing
+// from a full table is found again by solving the profiles it leads to. It
+// returns false when the search is stopped on the way.
+func (sk *skyline) trace(value int) ([]Square, bool) {
+	squares := []Square{}
+	for {
+		x, y := sk.lowest()
+		if x == -1 {
+			return squares, true
+		}
+		move := 0
+		if entry := sk.memo[string(sk.heights)]; entry != nil && entry.exact {
+			move = entry.move
+		} else if move = sk.findMove(x, y, value); move == 0 {
+			return nil, false
+		}
+		squares = append(squares, Square{x, y, move})
+		sk.raise(y, move, move)
+		value--
+	}
+}
+
+// findMove returns the size of a square at (x, y) that starts a completion
+// of the current profile with value squares, or 0 if there is none.
+func (sk *skyline) findMove(x, y, value int) int {
+	run := 0
+	for y+run < sk.board.cols && int(sk.heights[y+run]) == x {
+		run++
+	}
+	for size := Min(sk.board.maxSize(x, y), run); size >= 1; size-- {
+		if !sk.board.sizes.Allows(size) {
+			continue
+		}
+		sk.raise(y, size, size)
+		rest, ok := sk.solve(value - 1)
+		sk.raise(y, size, -size)
+		if sk.shared.stopped.Load() {
+			return 0
+		}
+		if ok && rest == value-1 {
+			return size
+		}
+	}
+	return 0
+}
+
+// searchSkyline runs the skyline engine from the initial squares already
+// placed on occupied, looking for tilings with fewer than bound squares.
+// Workers and the tracer are not used by it.
+func (s *Solver) searchSkyline(ctx context.Context, bd *board, occupied *grid, initialSquares []Square, bound int) (*shared, error) {
+	sh := newShared(ctx, 1, bound)
+	maxEntries := s.ProfileEntries
+	if maxEntries <= 0 {
+		maxEntries = maxProfileEntries
+	}
+	sk, err := newSkyline(bd, occupied, sh, maxEntries)
+	if err != nil {
+		return nil, err
+	}
+	start := append([]byte{}, sk.heights...)
+	value, ok := sk.solve(bound - len(initialSquares) - 1)
+	s.Iterations = sk.iterations
+	if !ok {
+		return sh, nil
+	}
+	copy(sk.heights, start)
+	squares, ok := sk.trace(value)
+	s.Iterations = sk.iterations
+	if !ok {
+		return sh, nil
+	}
+	s.MinSquares = len(initialSquares) + value
+	s.BestResult = append(append([]Square{}, initialSquares...), squares...)
+	return sh, nil
+}
@@ -0,0 +1,411 @@
+package tiling
+
+import (
+	"awesomeProject2/internal/blocks"
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+const initialBound = 999999
+
+type Square struct {
+	X, Y, Size int
+}
+
+func (s Square) String() string {
+	return fmt.Sprintf("%d %d %d", s.X+1, s.Y+1, s.Size)
+}
+
+// Solver keeps the state of a single search: the current upper bound,
+// the best tiling found so far and the number of visited nodes.
+type Solver struct {
+	MinSquares int
+	BestResult []Square
+	Iterations int
+	// Optimal is false when the search was stopped by its context before
+	// the whole tree was explored, so BestResult is only the best so far.
+	Optimal bool
+
+	// Workers is the number of goroutines used by the backtracking search.
+	// Values below 2 run the plain sequential backtracking.
+	Workers int
+	// Sizes limits the squares that may be used. A non-empty rule turns
+	// off scaling and the initial corner squares, which need every size.
+	Sizes SizeRule
+	// Perfect asks for tilings whose squares all differ in size, and
+	// DistinctSizes above 0 for tilings with at most that many different
+	// sizes. Coprime asks for a gcd of 1 of all sizes (Mrs. Perkins's
+	// quilt) and FaultFree for tilings that no straight line crosses
+	// without cutting a square. Like a size rule they turn off scaling and
+	// the initial corner squares, and they need the plain backtracking
+	// search.
+	Perfect       bool
+	DistinctSizes int
+	Coprime       bool
+	FaultFree     bool
+	// Engine is the search algorithm, Backtracking by default.
+	Engine Engine
+	// ProfileEntries caps the transposition table of the skyline engine,
+	// which holds about four million profiles when it is 0.
+	ProfileEntries int
+	// Pruning cuts branches that cannot beat the best tiling even with
+	// the fewest squares the uncovered area still needs, and tries the
+	// most promising square sizes first.
+	Pruning bool
+	// Tracer receives every step of the sequential backtracking search.
+	// It is nil by default, which keeps the search silent. The parallel
+	// and skyline searches do not report events.
+	Tracer Tracer
+	// OnCheckpoint, when set, receives the state of the sequential
+	// backtracking search of SolveRect every CheckpointEvery and once more when the
+	// search is stopped by its context.
+	OnCheckpoint    func(*Checkpoint)
+	CheckpointEvery time.Duration
+	// Resume continues SolveRect from a checkpoint of the same board and
+	// settings. The result is the one the uninterrupted search gives.
+	Resume *Checkpoint
+	// HeuristicSteps is the number of moves of the heuristic engine, a
+	// default when 0. Seed seeds its random choices.
+	HeuristicSteps int
+	Seed           int64
+	// SeedBound runs the heuristic before an exact engine and starts the
+	// exact search from its square count, so that only strictly better
+	// tilings are searched for. The heuristic tiling is kept if none is.
+	SeedBound bool
+}
+
+// ErrConstrainedEngine is returned when the perfect, distinct-size, quilt
+// or fault-free rules are used with an engine other than backtracking.
+var ErrConstrainedEngine = errors.New("perfect, distinct-size, quilt and fault-free tilings need the backtracking engine without a seed bound")
+
+// ErrInvalidBoard is returned for a board without cells.
+var ErrInvalidBoard = errors.New("a board needs at least one row and one column")
+
+// ErrNotSequential is returned when checkpoints are used with a search
+// other than the sequential backtracking.
+var ErrNotSequential = errors.New("checkpoints need the sequential backtracking search")
+
+func NewSolver() *Solver {
+	s := &Solver{Workers: 1, Pruning: true}
+	s.Reset()
+	return s
+}
+
+func (s *Solver) Reset() {
+	s.MinSquares = initialBound
+	s.BestResult = []Square{}
+	s.Iterations = 0
+	s.Optimal = false
+}
+
+// Solve finds the minimum tiling of an N×N board. If N is composite the
+// board is scaled down by its largest proper divisor first.
+func (s *Solver) Solve(ctx context.Context, N int) ([]Square, error) {
+	return s.SolveRect(ctx, N, N)
+}
+
+// SolveRect finds the minimum tiling of a board with N rows and M columns.
+// Square boards start from the three corner squares, rectangles are
+// searched from an empty board and scaled down by gcd(N, M).
+//
+// When ctx is cancelled or its deadline passes the search stops and the
+// best tiling found so far is returned with s.Optimal set to false. If no
+// tiling was found the error is ctx.Err(), or ErrInfeasible when the
+// whole tree was explored.
+func (s *Solver) SolveRect(ctx context.Context, N, M int) ([]Square, error) {
+	s.Reset()
+	if N < 1 || M < 1 {
+		return s.BestResult, fmt.Errorf("%w, got %dx%d", ErrInvalidBoard, N, M)
+	}
+	rows, cols, squareSize := N, M, 1
+	if s.shortcuts() {
+		rows, cols, squareSize = ScaleRect(N, M)
+	}
+	bd := newBoard(rows, cols)
+	bd.sizes = s.Sizes
+	bd.perfect, bd.maxDistinct = s.Perfect, s.DistinctSizes
+	bd.coprime, bd.faultFree = s.Coprime, s.FaultFree
+	bd.pruning = s.Pruning
+	bd.scale = squareSize
+
+	occupied := bd.newGrid()
+	initialSquares := []Square{}
+	if rows == cols && rows > 1 && s.shortcuts() {
+		initialSquares = placeInitialSquares(rows, occupied)
+	} else {
+		bd.cornerLimit = true
+	}
+	var cp *checkpointer
+	if s.OnCheckpoint != nil {
+		cp = &checkpointer{rows: N, cols: M, sizes: s.Sizes, perfect: s.Perfect, distinct: s.DistinctSizes, coprime: s.Coprime, faultFree: s.FaultFree, pruning: s.Pruning, every: s.CheckpointEvery, last: time.Now(), save: s.OnCheckpoint}
+	}
+	if s.Resume != nil && !s.Resume.matches(s, N, M, initialSquares) {
+		return s.BestResult, ErrCheckpointMismatch
+	}
+	if err := s.run(ctx, bd, occupied, initialSquares, cp); err != nil {
+		return s.BestResult, err
+	}
+
+	if squareSize != 1 {
+		s.BestResult = upscaleSquares(s.BestResult, squareSize)
+	}
+	return s.BestResult, nil
+}
+
+// run searches bd starting from the initialSquares already placed on
+// occupied and stores the outcome in s. cp, if not nil, writes the
+// checkpoints of the sequential search.
+func (s *Solver) run(ctx context.Context, bd *board, occupied *grid, initialSquares []Square, cp *checkpointer) error {
+	sequential := s.Engine == Backtracking && s.Workers <= 1
+	if (cp != nil || s.Resume != nil) && !sequential {
+		return ErrNotSequential
+	}
+	if bd.constrained() && (s.Engine != Backtracking || s.SeedBound) {
+		return ErrConstrainedEngine
+	}
+
+	if s.Engine == Heuristic || s.SeedBound {
+		squares, steps, err := s.searchHeuristic(ctx, bd, occupied, initialSquares)
+		if err != nil && (s.Engine == Heuristic || !errors.Is(err, ErrNoHeuristicTiling)) {
+			return err
+		}
+		s.Iterations = steps
+		if err == nil {
+			s.MinSquares, s.BestResult = len(squares), squares
+		}
+		if s.Engine == Heuristic {
+			s.Optimal = false
+			return nil
+		}
+	}
+	heuristicSteps := s.Iterations
+
+	var sh *shared
+	if s.Engine == Skyline {
+		var err error
+		if sh, err = s.searchSkyline(ctx, bd, occupied, initialSquares, s.MinSquares); err != nil {
+			return err
+		}
+	} else if s.Engine == DLX {
+		var err error
+		if sh, err = s.searchDLX(ctx, bd, occupied, initialSquares, s.MinSquares); err != nil {
+			return err
+		}
+	} else if s.Workers > 1 {
+		var err error
+		if sh, err = s.searchParallel(ctx, bd, initialSquares, s.MinSquares); err != nil {
+			return err
+		}
+	} else {
+		sh = newShared(ctx, 1, s.MinSquares)
+		b := newBranch(bd, sh, 0)
+		b.tracer = s.Tracer
+		b.checkpointer = cp
+		if s.Resume != nil {
+			b.resumeFrom(s.Resume, len(initialSquares))
+		}
+		for _, square := range initialSquares {
+			b.trace(Event{Kind: Placed, Square: square})
+		}
+		b.search(occupied, initialSquares, 0, 0)
+		s.Iterations = b.iterations
+		if b.best != nil {
+			s.MinSquares, s.BestResult = len(b.best), b.best
+		}
+	}
+	s.Iterations += heuristicSteps
+	s.Optimal = !sh.stopped.Load()
+
+	if s.MinSquares == initialBound {
+		if !s.Optimal {
+			return ctx.Err()
+		}
+		return ErrInfeasible
+	}
+	return nil
+}
+
+func upscaleSquares(squares []Square, scale int) []Square {
+	result := []Square{}
+	for _, square := range squares {
+		result = append(result, Square{
+			X:    square.X * scale,
+			Y:    square.Y * scale,
+			Size: square.Size * scale,
+		})
+	}
+	return result
+}
+
+func placeInitialSquares(N int, occupied *grid) []Square {
+	squares := []Square{}
+	size1 := (N + 1) / 2
+	squares = append(squares, occupied.placeSquare(0, 0, size1))
+	size2 := N / 2
+	squares = append(squares, occupied.placeSquare(0, size1, size2))
+	squares = append(squares, occupied.placeSquare(size1, 0, size2))
+	return squares
+}
+
+// shared is the state common to all branches of one solve. The bound
+// encodes the best square count together with the index of the subtree
+// that reached it, so that ties are resolved in the same order as in the
+// sequential search.
+type shared struct {
+	bound   atomic.Int64
+	tasks   int64
+	done    <-chan struct{}
+	stopped atomic.Bool
+}
+
+// newShared starts the bound at bound squares, so that only tilings with
+// fewer squares are accepted.
+func newShared(ctx context.Context, tasks int64, bound int) *shared {
+	sh := &shared{tasks: tasks, done: ctx.Done()}
+	sh.bound.Store(int64(bound) * tasks)
+	return sh
+}
+
+func (sh *shared) decode(key int64) (int, int64) {
+	return int(key / sh.tasks), key % sh.tasks
+}
+
+// branch runs the backtracking over one subtree of the search.
+type branch struct {
+	board      *board
+	shared     *shared
+	task       int64
+	iterations int
+	best       []Square
+	tracer     Tracer
+	// resume is the path of a checkpoint the branch is walking down, nil
+	// once the search has reached its last node.
+	resume       []Square
+	checkpointer *checkpointer
+	// nodeLimit stops the branch after that many nodes, 0 means no limit.
+	nodeLimit int
+}
+
+func newBranch(bd *board, sh *shared, task int64) *branch {
+	return &branch{board: bd, shared: sh, task: task}
+}
+
+// improves reports whether a tiling of count squares found in this branch
+// would beat the shared bound.
+func (b *branch) improves(count int) bool {
+	return int64(count)*b.shared.tasks+b.task < b.shared.bound.Load()
+}
+
+func (b *branch) tryUpdate(count int) bool {
+	key := int64(count)*b.shared.tasks + b.task
+	for {
+		old := b.shared.bound.Load()
+		if key >= old {
+			return false
+		}
+		if b.shared.bound.CompareAndSwap(old, key) {
+			return true
+		}
+	}
+}
+
+// stopped polls the context every blocks.CancelCheckInterval nodes and
+// reports whether the search has to be abandoned.
+func (b *branch) stopped() bool {
+	if b.iterations%blocks.CancelCheckInterval == 0 {
+		select {
+		case <-b.shared.done:
+			b.shared.stopped.Store(true)
+		default:
+		}
+		if b.nodeLimit > 0 && b.iterations >= b.nodeLimit {
+			b.shared.stopped.Store(true)
+		}
+	}
+	return b.shared.stopped.Load()
+}
+
+func (b *branch) search(occupied *grid, current []Square, from, depth int) {
+	b.iterations++
+	if b.stopped() {
+		if b.checkpointer != nil {
+			b.checkpoint(current)
+		}
+		return
+	}
+	if b.checkpointer != nil && b.iterations%blocks.CancelCheckInterval == 0 && time.Since(b.checkpointer.last) >= b.checkpointer.every {
+		b.checkpoint(current)
+	}
+	pos := occupied.findFirstFreePosition(from)
+	if b.board.rejects(occupied, current, from, pos) {
+		return
+	}
+
+	if pos == -1 {
+		b.trace(Event{Kind: Complete, Depth: depth, Count: len(current)})
+
+		if b.tryUpdate(len(current)) {
+			b.best = append([]Square{}, current...)
+			b.trace(Event{Kind: NewBest, Depth: depth, Tiling: b.best})
+		}
+		return
+	}
+
+	x, y := pos/b.board.cols, pos%b.board.cols
+	b.trace(Event{Kind: EnterCell, Depth: depth, Cell: Cell{x, y}})
+	maxSz := b.board.maxSize(x, y)
+	remaining := 0
+	if b.board.pruning {
+		remaining = b.lowerBound(occupied, x, y, current)
+		if !b.improves(len(current) + remaining) {
+			return
+		}
+		maxSz = occupied.largestFit(x, y, maxSz)
+	}
+
+	// A resumed branch skips the sizes tried before the checkpoint and
+	// descends into the square of the checkpoint path.
+	next, resumed := 0, false
+	if b.resume != nil {
+		if len(current) < len(b.resume) {
+			next = b.resume[len(current)].Size
+		} else {
+			b.resume = nil
+		}
+	}
+
+	var buf [64]int
+	for _, size := range b.board.candidates(occupied, x, y, maxSz, buf[:0]) {
+		if next != 0 {
+			if size != next {
+				continue
+			}
+			next, resumed = 0, true
+		}
+		b.trace(Event{Kind: Try, Depth: depth, Square: Square{x, y, size}})
+
+		if b.board.allowed(x, y, size, current) && occupied.canPlace(x, y, size) {
+			square := occupied.placeSquare(x, y, size)
+			current = append(current, square)
+			b.trace(Event{Kind: Placed, Depth: depth, Square: square})
+
+			if b.improves(len(current)) {
+				b.search(occupied, current, pos, depth+1)
+			}
+
+			current = current[:len(current)-1]
+			occupied.removeSquare(square)
+			b.trace(Event{Kind: Removed, Depth: depth, Square: square})
+		}
+		if resumed {
+			b.resume, resumed = nil, false
+		}
+
+		if !b.improves(len(current)+remaining) || b.shared.stopped.Load() {
+			break
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package tiling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// knownOptima are the minimum square counts of small prime boards.
+var knownOptima = []struct{ n, squares int }{
+	{2, 4}, {3, 6}, {5, 8}, {7, 9}, {11, 11}, {13, 11},
+}
+
+// solveChecked solves the N×N board with s and checks that the search
+// finished and that the tiling is valid.
+func solveChecked(t *testing.T, s *Solver, n int) []Square {
+	t.Helper()
+	squares, err := s.Solve(context.Background(), n)
+	if err != nil {
+		t.Fatalf("Solve(%d): %v", n, err)
+	}
+	if !s.Optimal {
+		t.Fatalf("Solve(%d) did not finish", n)
+	}
+	if err := Verify(n, n, squares); err != nil {
+		t.Fatalf("Solve(%d): %v", n, err)
+	}
+	return squares
+}
+
+func TestSolveKnownOptima(t *testing.T) {
+	paths := []struct {
+		name  string
+		setup func(*Solver)
+	}{
+		{"sequential", func(s *Solver) {}},
+		{"parallel", func(s *Solver) { s.Workers = 4 }},
+		{"skyline", func(s *Solver) { s.Engine = Skyline }},
+		{"skyline-full-table", func(s *Solver) { s.Engine, s.ProfileEntries = Skyline, 16 }},
+		{"dlx", func(s *Solver) { s.Engine = DLX }},
+		{"seed-bound", func(s *Solver) { s.SeedBound = true }},
+	}
+	for _, tc := range knownOptima {
+		for _, path := range paths {
+			t.Run(fmt.Sprintf("%s/%d", path.name, tc.n), func(t *testing.T) {
+				s := NewSolver()
+				path.setup(s)
+				squares := solveChecked(t, s, tc.n)
+				if len(squares) != tc.squares || s.MinSquares != tc.squares {
+					t.Errorf("got %d squares, want %d", len(squares), tc.squares)
+				}
+			})
+		}
+	}
+}
+
+// The shared bound breaks ties by task index, so every worker count must
+// return the tiling of the sequential search, not just one as small.
+func TestParallelMatchesSequential(t *testing.T) {
+	for _, tc := range knownOptima {
+		want := solveChecked(t, NewSolver(), tc.n)
+		for _, workers := range []int{2, 3, 8} {
+			s := NewSolver()
+			s.Workers = workers
+			if got := solveChecked(t, s, tc.n); !slices.Equal(got, want) {
+				t.Errorf("N=%d, %d workers: got %v, want %v", tc.n, workers, got, want)
+			}
+		}
+	}
+}
+
+// checkpointRecorder rebuilds from the events of a sequential search the
+// checkpoint the search would write at each of its nodes.
+type checkpointRecorder struct {
+	n           int
+	path, best  []Square
+	nodes       int
+	checkpoints []*Checkpoint
+}
+
+func (r *checkpointRecorder) Trace(e Event) {
+	switch e.Kind {
+	case Placed:
+		r.path = append(r.path, e.Square)
+	case Removed:
+		r.path = r.path[:len(r.path)-1]
+	case NewBest:
+		r.best = slices.Clone(e.Tiling)
+	case EnterCell, Complete:
+		// Every node reports exactly one of the two.
+		r.nodes++
+		r.checkpoints = append(r.checkpoints, &Checkpoint{
+			Rows:       r.n,
+			Cols:       r.n,
+			Pruning:    true,
+			Path:       slices.Clone(r.path),
+			Best:       slices.Clone(r.best),
+			Iterations: r.nodes,
+		})
+	}
+}
+
+// Resuming from the checkpoint of any node must end with the tiling and
+// the iteration count of the uninterrupted search.
+func TestResumeMatchesUninterrupted(t *testing.T) {
+	for _, tc := range knownOptima {
+		recorder := &checkpointRecorder{n: tc.n}
+		s := NewSolver()
+		s.Tracer = recorder
+		want := solveChecked(t, s, tc.n)
+		if len(want) != tc.squares {
+			t.Fatalf("N=%d: got %d squares, want %d", tc.n, len(want), tc.squares)
+		}
+
+		for i, cp := range recorder.checkpoints {
+			resumed := NewSolver()
+			resumed.Resume = cp
+			if got := solveChecked(t, resumed, tc.n); !slices.Equal(got, want) {
+				t.Errorf("N=%d, resumed at node %d: got %v, want %v", tc.n, i+1, got, want)
+			}
+			if resumed.Iterations != s.Iterations {
+				t.Errorf("N=%d, resumed at node %d: got %d iterations, want %d", tc.n, i+1, resumed.Iterations, s.Iterations)
+			}
+		}
+	}
+}
+
+// A 1×1 board cannot be split, whatever the rules, and must not panic in
+// the lower bound when no square fits.
+func TestSolveOneByOneInfeasible(t *testing.T) {
+	rules := []struct {
+		name  string
+		setup func(*Solver)
+	}{
+		{"none", func(s *Solver) {}},
+		{"min-size", func(s *Solver) { s.Sizes.Min = 2 }},
+		{"max-size", func(s *Solver) { s.Sizes.Max = 1 }},
+		{"allowed", func(s *Solver) { s.Sizes.Allowed = []int{1} }},
+		{"perfect", func(s *Solver) { s.Perfect = true }},
+		{"distinct", func(s *Solver) { s.DistinctSizes = 1 }},
+		{"coprime", func(s *Solver) { s.Coprime = true }},
+		{"fault-free", func(s *Solver) { s.FaultFree = true }},
+		{"no-pruning", func(s *Solver) { s.Pruning = false }},
+		{"parallel", func(s *Solver) { s.Workers = 4 }},
+		{"skyline", func(s *Solver) { s.Engine = Skyline }},
+		{"dlx", func(s *Solver) { s.Engine = DLX }},
+	}
+	for _, rule := range rules {
+		t.Run(rule.name, func(t *testing.T) {
+			s := NewSolver()
+			rule.setup(s)
+			squares, err := s.Solve(context.Background(), 1)
+			if !errors.Is(err, ErrInfeasible) {
+				t.Errorf("got %v, %v, want ErrInfeasible", squares, err)
+			}
+		})
+	}
+}
+
+// The heuristic engine stops its construction at the deadline and still
+// returns a complete tiling.
+func TestHeuristicStopsAtDeadline(t *testing.T) {
+	const n = 1009
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	s := NewSolver()
+	s.Engine = Heuristic
+	start := time.Now()
+	squares, err := s.Solve(ctx, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("the heuristic took %v", elapsed)
+	}
+	if err := Verify(n, n, squares); err != nil {
+		t.Error(err)
+	}
+}
+
+// Boards without cells are refused before anything divides by their
+// sides.
+func TestSolveInvalidBoard(t *testing.T) {
+	for _, size := range [][2]int{{0, 0}, {0, 5}, {5, 0}, {-3, -3}, {2, -1}} {
+		if _, err := NewSolver().SolveRect(context.Background(), size[0], size[1]); !errors.Is(err, ErrInvalidBoard) {
+			t.Errorf("SolveRect(%d, %d): got %v, want ErrInvalidBoard", size[0], size[1], err)
+		}
+		l := &Layout{Rows: size[0], Cols: size[1], Fixed: []Square{{X: 0, Y: 0, Size: 1}}}
+		if _, err := NewSolver().SolveLayout(context.Background(), l); !errors.Is(err, ErrInvalidBoard) {
+			t.Errorf("SolveLayout(%dx%d): got %v, want ErrInvalidBoard", size[0], size[1], err)
+		}
+	}
+	l := &Layout{Rows: 2, Cols: 2, Blocked: [][]bool{{true}}}
+	if _, err := NewSolver().SolveLayout(context.Background(), l); err == nil {
+		t.Error("SolveLayout accepted blocked cells of another size")
+	}
+}
+
+// The dlx engine refuses an exact cover matrix beyond its cap instead of
+// building it, and stops building when the context is done.
+func TestDLXLimits(t *testing.T) {
+	s := NewSolver()
+	s.Engine, s.Sizes.Max = DLX, 60
+	start := time.Now()
+	if _, err := s.Solve(context.Background(), 61); !errors.Is(err, ErrExactCoverTooLarge) {
+		t.Errorf("got %v, want ErrExactCoverTooLarge", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("refusing the matrix took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s = NewSolver()
+	s.Engine, s.Sizes.Max = DLX, 30
+	if _, err := s.Solve(ctx, 31); !errors.Is(err, context.Canceled) || s.Optimal {
+		t.Errorf("got %v, optimal %v, want context.Canceled", err, s.Optimal)
+	}
+}
+
+// A bound the initial squares already reach leaves nothing to search for,
+// and must not be taken by MinCover as no bound at all.
+func TestDLXBoundReached(t *testing.T) {
+	bd := newBoard(5, 5)
+	occupied := bd.newGrid()
+	initial := placeInitialSquares(5, occupied)
+	s := NewSolver()
+	sh, err := s.searchDLX(context.Background(), bd, occupied, initial, len(initial))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sh.stopped.Load() || s.MinSquares != initialBound || len(s.BestResult) != 0 {
+		t.Errorf("got %d squares %v, want none", s.MinSquares, s.BestResult)
+	}
+}
+
+// A panic in a worker of the parallel search comes back as an error
+// instead of ending the process.
+func TestParallelWorkerPanic(t *testing.T) {
+	var tasks atomic.Int64
+	taskHook = func([]Square) {
+		if tasks.Add(1) == 3 {
+			panic("task failed")
+		}
+	}
+	t.Cleanup(func() { taskHook = nil })
+
+	s := NewSolver()
+	s.Workers = 4
+	if _, err := s.Solve(context.Background(), 13); !errors.Is(err, ErrWorkerPanic) {
+		t.Errorf("got %v, want ErrWorkerPanic", err)
+	}
+}
+
+// rectOptima are the minimum square counts of small rectangles.
+var rectOptima = []struct{ rows, cols, squares int }{
+	{1, 5, 5}, {2, 3, 3}, {3, 5, 4}, {4, 7, 5}, {5, 6, 5}, {5, 8, 5},
+	{6, 7, 5}, {7, 9, 6}, {11, 13, 6},
+}
+
+// Every engine finds the known optimum of a rectangle in both of its
+// orientations.
+func TestSolveRectKnownOptima(t *testing.T) {
+	paths := []struct {
+		name  string
+		setup func(*Solver)
+	}{
+		{"sequential", func(s *Solver) {}},
+		{"parallel", func(s *Solver) { s.Workers = 4 }},
+		{"skyline", func(s *Solver) { s.Engine = Skyline }},
+		{"dlx", func(s *Solver) { s.Engine = DLX }},
+	}
+	for _, tc := range rectOptima {
+		for _, size := range [][2]int{{tc.rows, tc.cols}, {tc.cols, tc.rows}} {
+			for _, path := range paths {
+				t.Run(fmt.Sprintf("%s/%dx%d", path.name, size[0], size[1]), func(t *testing.T) {
+					s := NewSolver()
+					path.setup(s)
+					squares, err := s.SolveRect(context.Background(), size[0], size[1])
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !s.Optimal {
+						t.Fatal("the search did not finish")
+					}
+					if err := Verify(size[0], size[1], squares); err != nil {
+						t.Fatal(err)
+					}
+					if len(squares) != tc.squares {
+						t.Errorf("got %d squares, want %d", len(squares), tc.squares)
+					}
+				})
+			}
+		}
+	}
+}
+
+// With a context that is already cancelled every engine returns at once
+// and proves nothing. The sequential search completes its first dive
+// before it polls the context, so it has a best-so-far tiling. The other
+// engines may have none, in which case they return the context error.
+func TestSolveCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	paths := []struct {
+		name      string
+		setup     func(*Solver)
+		hasTiling bool
+	}{
+		{"sequential", func(s *Solver) {}, true},
+		{"parallel", func(s *Solver) { s.Workers = 4 }, false},
+		{"skyline", func(s *Solver) { s.Engine = Skyline }, false},
+		{"dlx", func(s *Solver) { s.Engine = DLX }, false},
+		{"heuristic", func(s *Solver) { s.Engine = Heuristic }, true},
+	}
+	for _, size := range [][2]int{{41, 41}, {37, 53}} {
+		for _, path := range paths {
+			t.Run(fmt.Sprintf("%s/%dx%d", path.name, size[0], size[1]), func(t *testing.T) {
+				s := NewSolver()
+				path.setup(s)
+				start := time.Now()
+				squares, err := s.SolveRect(ctx, size[0], size[1])
+				if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+					t.Errorf("took %v", elapsed)
+				}
+				if s.Optimal {
+					t.Error("a cancelled search claims to be optimal")
+				}
+				if err != nil {
+					if path.hasTiling || !errors.Is(err, context.Canceled) {
+						t.Fatalf("got %v", err)
+					}
+					return
+				}
+				if err := Verify(size[0], size[1], squares); err != nil {
+					t.Fatalf("best-so-far tiling: %v", err)
+				}
+				if len(squares) != s.MinSquares {
+					t.Errorf("got %d squares, MinSquares %d", len(squares), s.MinSquares)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package tiling
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// EventKind tells what happened in an Event.
+type EventKind int
+
+const (
+	// EnterCell is reported when the search picks the next free cell.
+	EnterCell EventKind = iota
+	// Try is reported before a square size is checked at the free cell.
+	Try
+	// Placed is reported after a square is put on the board.
+	Placed
+	// Removed is reported after a square is taken off the board again.
+	Removed
+	// Complete is reported when the board is covered.
+	Complete
+	// NewBest is reported when the search completes a tiling with fewer
+	// squares than the best one so far.
+	NewBest
+)
+
+var eventNames = []string{"enter-cell", "try", "place", "remove", "complete", "new-best"}
+
+func (k EventKind) String() string {
+	if int(k) < len(eventNames) {
+		return eventNames[k]
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// Event is a step of the sequential backtracking search. Cells and squares
+// are in the coordinates of the board passed to the solver, not of the
+// scaled one.
+type Event struct {
+	Kind EventKind
+	// Depth is the recursion depth of the search.
+	Depth int
+	// Cell is the free cell of an EnterCell event.
+	Cell Cell
+	// Square is the square tried, placed or removed.
+	Square Square
+	// Count is the number of squares of a Complete event.
+	Count int
+	// Tiling is the new best tiling of a NewBest event.
+	Tiling []Square
+}
+
+// Tracer receives the events of the sequential backtracking search. A nil
+// Tracer keeps the search silent.
+type Tracer interface {
+	Trace(e Event)
+}
+
+// NopTracer drops every event.
+type NopTracer struct{}
+
+func (NopTracer) Trace(Event) {}
+
+// multiTracer passes every event to several tracers.
+type multiTracer []Tracer
+
+func (m multiTracer) Trace(e Event) {
+	for _, t := range m {
+		t.Trace(e)
+	}
+}
+
+// MultiTracer returns a Tracer that passes every event to all the given
+// non-nil tracers, or nil if there are none.
+func MultiTracer(tracers ...Tracer) Tracer {
+	var m multiTracer
+	for _, t := range tracers {
+		if t != nil {
+			m = append(m, t)
+		}
+	}
+	switch len(m) {
+	case 0:
+		return nil
+	case 1:
+		return m[0]
+	}
+	return m
+}
+
+// TextTracer prints the events as indented human readable lines.
+type TextTracer struct {
+	w io.Writer
+}
+
+func NewTextTracer(w io.Writer) *TextTracer {
+	return &TextTracer{w: w}
+}
+
+func (t *TextTracer) Trace(e Event) {
+	indent := strings.Repeat("  ", e.Depth)
+	switch e.Kind {
+	case EnterCell:
+		fmt.Fprintf(t.w, "%sFound free position at (%d, %d)\n", indent, e.Cell.X, e.Cell.Y)
+	case Try:
+		fmt.Fprintf(t.w, "%sAttempting square at (%d, %d) size %d\n", indent, e.Square.X, e.Square.Y, e.Square.Size)
+	case Placed:
+		fmt.Fprintf(t.w, "%sPlaced square at (%d, %d) size %d\n", indent, e.Square.X, e.Square.Y, e.Square.Size)
+	case Removed:
+		fmt.Fprintf(t.w, "%sRemoving square at (%d, %d) size %d\n", indent, e.Square.X, e.Square.Y, e.Square.Size)
+	case Complete:
+		fmt.Fprintf(t.w, "%sCompleted configuration with %d squares\n", indent, e.Count)
+	case NewBest:
+		fmt.Fprintln(t.w, "--- New Best Result ---")
+		for _, square := range e.Tiling {
+			fmt.Fprintln(t.w, square.String())
+		}
+		fmt.Fprintln(t.w, "-----------------------")
+	}
+}
+
+// JSONTracer writes one JSON object per event. Coordinates are 1-based,
+// as in the JSON form of Square.
+type JSONTracer struct {
+	encoder *json.Encoder
+}
+
+func NewJSONTracer(w io.Writer) *JSONTracer {
+	return &JSONTracer{encoder: json.NewEncoder(w)}
+}
+
+type jsonEvent struct {
+	Event  string   `json:"event"`
+	Depth  int      `json:"depth"`
+	X      int      `json:"x,omitempty"`
+	Y      int      `json:"y,omitempty"`
+	Square *Square  `json:"square,omitempty"`
+	Count  int      `json:"count,omitempty"`
+	Tiling []Square `json:"tiling,omitempty"`
+}
+
+func (t *JSONTracer) Trace(e Event) {
+	je := jsonEvent{Event: e.Kind.String(), Depth: e.Depth}
+	switch e.Kind {
+	case EnterCell:
+		je.X, je.Y = e.Cell.X+1, e.Cell.Y+1
+	case Try, Placed, Removed:
+		je.Square = &e.Square
+	case Complete:
+		je.Count = e.Count
+	case NewBest:
+		je.Count, je.Tiling = len(e.Tiling), e.Tiling
+	}
+	t.encoder.Encode(je)
+}
+
+// ParseTracer returns the tracer called name writing to w: "none" for no
+// tracing, "text" or "json".
+func ParseTracer(name string, w io.Writer) (Tracer, error) {
+	switch name {
+	case "none", "":
+		return nil, nil
+	case "text":
+		return NewTextTracer(w), nil
+	case "json":
+		return NewJSONTracer(w), nil
+	}
+	return nil, fmt.Errorf("unknown tracer %q", name)
+}
+
+// trace reports an event to the tracer of the branch, if any.
+func (b *branch) trace(e Event) {
+	if b.tracer == nil {
+		return
+	}
+	if scale := b.board.scale; scale != 1 {
+		e.Cell = Cell{e.Cell.X * scale, e.Cell.Y * scale}
+		e.Square = Square{e.Square.X * scale, e.Square.Y * scale, e.Square.Size * scale}
+		if e.Tiling != nil {
+			e.Tiling = upscaleSquares(e.Tiling, scale)
+		}
+	} else if e.Tiling != nil {
+		e.Tiling = append([]Square{}, e.Tiling...)
+	}
+	b.tracer.Trace(e)
+}
@@ -0,0 +1,61 @@
+package tiling
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// The text and JSON Lines traces of a tiny search are compared with the
+// golden files in testdata. Run the tests with -update after a deliberate
+// change of the format.
+func TestTraceGolden(t *testing.T) {
+	tests := []struct{ tracer, golden string }{
+		{"text", "trace_2x3.txt"},
+		{"json", "trace_2x3.jsonl"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.tracer, func(t *testing.T) {
+			var buf bytes.Buffer
+			tracer, err := ParseTracer(tc.tracer, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := NewSolver()
+			s.Tracer = tracer
+			if _, err := s.SolveRect(context.Background(), 2, 3); err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join("testdata", tc.golden)
+			if *update {
+				if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("the trace differs from %s:\n%s", path, buf.String())
+			}
+		})
+	}
+}
+
+func TestParseTracer(t *testing.T) {
+	for _, name := range []string{"", "none"} {
+		if tracer, err := ParseTracer(name, nil); tracer != nil || err != nil {
+			t.Errorf("%q: got %v, %v, want no tracer", name, tracer, err)
+		}
+	}
+	if _, err := ParseTracer("xml", nil); err == nil {
+		t.Error("ParseTracer accepted xml")
+	}
+}
@@ -0,0 +1,65 @@
+package tiling
+
+import "math/bits"
+
+func Min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func Max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// findFirstFreePosition returns the first free cell in row-major order
+// starting from pos, or -1 if the board is full. The search only ever
+// fills cells, so the caller can pass the position found one level up.
+func (g *grid) findFirstFreePosition(pos int) int {
+	x, y := pos/g.cols, pos%g.cols
+	for ; x < g.rows; x, y = x+1, 0 {
+		row := g.row(x)
+		for w := y / wordBits; w < g.words; w++ {
+			free := ^row[w] & segmentMask(w, y, g.cols-y)
+			if free != 0 {
+				return x*g.cols + w*wordBits + bits.TrailingZeros64(free)
+			}
+		}
+	}
+	return -1
+}
+
+func (g *grid) canPlace(x, y, size int) bool {
+	for i := x; i < x+size; i++ {
+		row := g.row(i)
+		for w := y / wordBits; w <= (y+size-1)/wordBits; w++ {
+			if row[w]&segmentMask(w, y, size) != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (g *grid) placeSquare(x, y, size int) Square {
+	for i := x; i < x+size; i++ {
+		row := g.row(i)
+		for w := y / wordBits; w <= (y+size-1)/wordBits; w++ {
+			row[w] |= segmentMask(w, y, size)
+		}
+	}
+	return Square{x, y, size}
+}
+
+func (g *grid) removeSquare(square Square) {
+	for i := square.X; i < square.X+square.Size; i++ {
+		row := g.row(i)
+		for w := square.Y / wordBits; w <= (square.Y+square.Size-1)/wordBits; w++ {
+			row[w] &^= segmentMask(w, square.Y, square.Size)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package tiling
+
+import (
+	"awesomeProject2/internal/blocks"
+	"fmt"
+	"strings"
+)
+
+// Cell is a board cell, 0-based like the coordinates of Square.
+type Cell struct {
+	X, Y int
+}
+
+func (c Cell) String() string {
+	return fmt.Sprintf("(%d, %d)", c.X+1, c.Y+1)
+}
+
+// VerifyError lists everything that keeps squares from being an exact
+// tiling of a board. It keeps at most blocks.MaxReportedCells cells of
+// each kind.
+type VerifyError struct {
+	// InvalidBoard describes a board without cells or with blocked cells
+	// of another size. Nothing else is checked then.
+	InvalidBoard string
+	// Invalid holds squares with a non-positive size.
+	Invalid []Square
+	// OutOfBounds holds cells covered by a square but outside the board.
+	OutOfBounds []Cell
+	// Blocked holds blocked cells of a layout covered by a square.
+	Blocked []Cell
+	// Overlaps holds cells covered by more than one square.
+	Overlaps []Cell
+	// Gaps holds free cells not covered by any square.
+	Gaps []Cell
+	// MissingFixed holds fixed squares of a layout absent from the tiling.
+	MissingFixed []Square
+}
+
+func (e *VerifyError) Error() string {
+	var parts []string
+	if e.InvalidBoard != "" {
+		parts = append(parts, "invalid board: "+e.InvalidBoard)
+	}
+	if len(e.Invalid) > 0 {
+		parts = append(parts, "invalid squares: "+blocks.JoinShort(e.Invalid))
+	}
+	if len(e.OutOfBounds) > 0 {
+		parts = append(parts, "out of bounds: "+blocks.JoinShort(e.OutOfBounds))
+	}
+	if len(e.Blocked) > 0 {
+		parts = append(parts, "blocked cells covered: "+blocks.JoinShort(e.Blocked))
+	}
+	if len(e.Overlaps) > 0 {
+		parts = append(parts, "overlaps: "+blocks.JoinShort(e.Overlaps))
+	}
+	if len(e.Gaps) > 0 {
+		parts = append(parts, "gaps: "+blocks.JoinShort(e.Gaps))
+	}
+	if len(e.MissingFixed) > 0 {
+		parts = append(parts, "missing fixed squares: "+blocks.JoinShort(e.MissingFixed))
+	}
+	return "invalid tiling: " + strings.Join(parts, "; ")
+}
+
+func (e *VerifyError) empty() bool {
+	return e.InvalidBoard == "" && len(e.Invalid) == 0 && len(e.OutOfBounds) == 0 && len(e.Blocked) == 0 &&
+		len(e.Overlaps) == 0 && len(e.Gaps) == 0 && len(e.MissingFixed) == 0
+}
+
+// appendOutOfBounds adds the cells of square that lie outside a rows×cols
+// board. Every row of such a square has at least one outside cell, so the
+// loop stops soon after the report is full.
+func appendOutOfBounds(cells []Cell, square Square, rows, cols int) []Cell {
+	for i := square.X; i < square.X+square.Size && len(cells) < blocks.MaxReportedCells; i++ {
+		for j := square.Y; j < square.Y+square.Size && len(cells) < blocks.MaxReportedCells; j++ {
+			if i >= 0 && i < rows && j >= 0 && j < cols {
+				j = cols - 1
+				continue
+			}
+			cells = blocks.AppendCell(cells, Cell{i, j})
+		}
+	}
+	return cells
+}
+
+// Verify checks that squares cover the rows×cols board exactly: every
+// cell once, nothing outside. It returns a *VerifyError naming the
+// offending cells, or nil.
+func Verify(rows, cols int, squares []Square) error {
+	return VerifyLayout(&Layout{Rows: rows, Cols: cols}, squares)
+}
+
+// blockedFits reports whether the blocked cells of l have its dimensions.
+func blockedFits(l *Layout) bool {
+	if len(l.Blocked) != l.Rows {
+		return false
+	}
+	for _, row := range l.Blocked {
+		if len(row) != l.Cols {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyLayout checks that squares cover every free cell of a layout once,
+// leave its blocked cells uncovered and include all of its fixed squares.
+func VerifyLayout(l *Layout, squares []Square) error {
+	e := &VerifyError{}
+	if l.Rows < 1 || l.Cols < 1 {
+		e.InvalidBoard = fmt.Sprintf("%dx%d has no cells", l.Rows, l.Cols)
+		return e
+	}
+	if l.Blocked != nil && !blockedFits(l) {
+		e.InvalidBoard = fmt.Sprintf("blocked cells do not match the %dx%d board", l.Rows, l.Cols)
+		return e
+	}
+	counts := make([][]int, l.Rows)
+	for i := range counts {
+		counts[i] = make([]int, l.Cols)
+	}
+
+	present := map[Square]bool{}
+	for _, square := range squares {
+		present[square] = true
+		if square.Size <= 0 {
+			e.Invalid = append(e.Invalid, square)
+			continue
+		}
+		x0, x1 := Max(square.X, 0), Min(square.X+square.Size, l.Rows)
+		y0, y1 := Max(square.Y, 0), Min(square.Y+square.Size, l.Cols)
+		for i := x0; i < x1; i++ {
+			for j := y0; j < y1; j++ {
+				counts[i][j]++
+			}
+		}
+		if x0 != square.X || y0 != square.Y || x1 != square.X+square.Size || y1 != square.Y+square.Size {
+			e.OutOfBounds = appendOutOfBounds(e.OutOfBounds, square, l.Rows, l.Cols)
+		}
+	}
+
+	for i, row := range counts {
+		for j, count := range row {
+			blocked := l.Blocked != nil && l.Blocked[i][j]
+			switch {
+			case blocked && count > 0:
+				e.Blocked = blocks.AppendCell(e.Blocked, Cell{i, j})
+			case count > 1:
+				e.Overlaps = blocks.AppendCell(e.Overlaps, Cell{i, j})
+			case count == 0 && !blocked:
+				e.Gaps = blocks.AppendCell(e.Gaps, Cell{i, j})
+			}
+		}
+	}
+	for _, square := range l.Fixed {
+		if !present[square] {
+			e.MissingFixed = append(e.MissingFixed, square)
+		}
+	}
+
+	if e.empty() {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,53 @@
+package tiling
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVerifyLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  *Layout
+		squares []Square
+		check   func(*VerifyError) bool
+	}{
+		{"valid", &Layout{Rows: 2, Cols: 3}, []Square{{0, 0, 2}, {0, 2, 1}, {1, 2, 1}}, nil},
+		{"negative rows", &Layout{Rows: -1, Cols: 3}, nil,
+			func(e *VerifyError) bool { return e.InvalidBoard != "" }},
+		{"zero cols", &Layout{Rows: 2, Cols: 0}, []Square{{0, 0, 1}},
+			func(e *VerifyError) bool { return e.InvalidBoard != "" }},
+		{"short blocked rows", &Layout{Rows: 2, Cols: 2, Blocked: [][]bool{{false, true}}}, nil,
+			func(e *VerifyError) bool { return e.InvalidBoard != "" }},
+		{"overlap", &Layout{Rows: 2, Cols: 2}, []Square{{0, 0, 2}, {1, 1, 1}},
+			func(e *VerifyError) bool { return len(e.Overlaps) == 1 }},
+		{"gap", &Layout{Rows: 2, Cols: 2}, []Square{{0, 0, 1}, {0, 1, 1}, {1, 0, 1}},
+			func(e *VerifyError) bool { return len(e.Gaps) == 1 }},
+		{"out of bounds", &Layout{Rows: 2, Cols: 2}, []Square{{0, 0, 2}, {1, 1, 2}},
+			func(e *VerifyError) bool { return len(e.OutOfBounds) == 3 && len(e.Overlaps) == 1 }},
+		{"invalid size", &Layout{Rows: 1, Cols: 1}, []Square{{0, 0, 0}},
+			func(e *VerifyError) bool { return len(e.Invalid) == 1 && len(e.Gaps) == 1 }},
+		{"blocked covered", &Layout{Rows: 1, Cols: 2, Blocked: [][]bool{{false, true}}}, []Square{{0, 0, 1}, {0, 1, 1}},
+			func(e *VerifyError) bool { return len(e.Blocked) == 1 }},
+		{"missing fixed", &Layout{Rows: 1, Cols: 2, Fixed: []Square{{0, 1, 1}}}, []Square{{0, 0, 1}},
+			func(e *VerifyError) bool { return len(e.MissingFixed) == 1 && len(e.Gaps) == 1 }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifyLayout(tc.layout, tc.squares)
+			if tc.check == nil {
+				if err != nil {
+					t.Fatalf("got %v, want nil", err)
+				}
+				return
+			}
+			var e *VerifyError
+			if !errors.As(err, &e) {
+				t.Fatalf("got %v, want a *VerifyError", err)
+			}
+			if !tc.check(e) {
+				t.Errorf("unexpected report: %v", e)
+			}
+		})
+	}
+}
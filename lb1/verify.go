@@ -0,0 +1,93 @@
+package main
+
+import (
+	"awesomeProject2/cube"
+	"awesomeProject2/tiling"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// runVerify implements the "verify" subcommand: it reads a tiling in the
+// text or JSON format and checks that it covers the board exactly.
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	rows := fs.Int("n", 0, "Number of rows of the board")
+	cols := fs.Int("m", 0, "Number of columns of the board (defaults to -n)")
+	asJSON := fs.Bool("json", false, "Read the tiling as JSON")
+	layoutPath := fs.String("layout", "", "Check the tiling against the layout in this file")
+	asCubes := fs.Bool("cube", false, "Read a split of the N×N×N block into \"x y z size\" cubes instead")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: verify [flags] [file]")
+		fmt.Fprintln(fs.Output(), "Reads the tiling from file, or from stdin when no file is given.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var in io.Reader = os.Stdin
+	if fs.NArg() > 0 {
+		file, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+	if *asCubes {
+		return verifyCubes(in, *rows)
+	}
+
+	var squares []tiling.Square
+	if *asJSON {
+		t, err := tiling.ReadJSON(in)
+		if err != nil {
+			return err
+		}
+		squares = t.Squares
+		if *rows == 0 {
+			*rows, *cols = t.Rows, t.Cols
+		}
+	} else {
+		var err error
+		if squares, err = tiling.ReadText(in); err != nil {
+			return err
+		}
+	}
+	if *cols == 0 {
+		*cols = *rows
+	}
+
+	layout := &tiling.Layout{Rows: *rows, Cols: *cols}
+	if *layoutPath != "" {
+		var err error
+		if layout, err = readLayout(*layoutPath); err != nil {
+			return err
+		}
+	}
+	if layout.Rows <= 0 || layout.Cols <= 0 {
+		return fmt.Errorf("board size is unknown, pass -n and -m")
+	}
+
+	if err := tiling.VerifyLayout(layout, squares); err != nil {
+		return err
+	}
+	fmt.Printf("OK: %d squares tile the %dx%d board\n", len(squares), layout.Rows, layout.Cols)
+	return nil
+}
+
+// verifyCubes checks that the cubes read from in fill the n×n×n block.
+func verifyCubes(in io.Reader, n int) error {
+	cubes, err := cube.ReadText(in)
+	if err != nil {
+		return err
+	}
+	if n <= 0 {
+		return fmt.Errorf("block size is unknown, pass -n")
+	}
+	if err := cube.Verify(n, cubes); err != nil {
+		return err
+	}
+	fmt.Printf("OK: %d cubes fill the %dx%dx%d block\n", len(cubes), n, n, n)
+	return nil
+}